@@ -0,0 +1,192 @@
+package bot
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// checkChannelNickname returns an error if the nickname is already used for a
+// channel other than chID or for a guild. Channel nicknames and guild aliases
+// share the same namespace, so that renick and unalias are never ambiguous.
+func (s *Session) checkChannelNickname(ctx context.Context, nickname string, chID discord.ChannelID) error {
+	id, err := s.store.ChannelFromNickname(ctx, nickname)
+	if err == nil && id != chID {
+		return usedAliasError{nickname}
+	}
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	if _, err := s.store.GuildFromAlias(ctx, nickname); !errors.Is(err, store.ErrNotFound) {
+		if err != nil {
+			return err
+		}
+		return usedAliasError{nickname}
+	}
+
+	return nil
+}
+
+// checkGuildAlias returns an error if the alias is already used for a guild
+// other than guildID or for a channel.
+func (s *Session) checkGuildAlias(ctx context.Context, alias string, guildID discord.GuildID) error {
+	id, err := s.store.GuildFromAlias(ctx, alias)
+	if err == nil && id != guildID {
+		return usedAliasError{alias}
+	}
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	if _, err := s.store.ChannelFromNickname(ctx, alias); !errors.Is(err, store.ErrNotFound) {
+		if err != nil {
+			return err
+		}
+		return usedAliasError{alias}
+	}
+
+	return nil
+}
+
+type usedAliasError struct {
+	alias string
+}
+
+func (e usedAliasError) Error() string {
+	return fmt.Sprintf("%q is already used for something else", e.alias)
+}
+
+// aliasErrorResponse returns the response for an error from checking an alias.
+func (s *Session) aliasErrorResponse(req *twicmdproto.ExecuteRequest, err error) *twicmdproto.ExecuteResponse {
+	var used usedAliasError
+	if errors.As(err, &used) {
+		return twicmd.StatusResponse(used.Error())
+	}
+	return s.internalErrorResponse(req, err)
+}
+
+func (s *Session) executeAliases(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	nicknames, err := s.store.ChannelNicknames(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	aliases, err := s.store.GuildAliases(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if len(nicknames) == 0 && len(aliases) == 0 {
+		return twicmd.StatusResponse("No aliases.")
+	}
+
+	var buf strings.Builder
+
+	if len(nicknames) > 0 {
+		buf.WriteString("Channels:\n")
+		for _, chID := range sortedKeys(nicknames) {
+			fmt.Fprintf(&buf, "%s: ", nicknames[chID])
+
+			channel, err := s.State.Cabinet.Channel(chID)
+			if err != nil {
+				buf.WriteString("deleted channel\n")
+				continue
+			}
+
+			if channel.GuildID.IsValid() {
+				fmt.Fprintf(&buf, "#%s", ChannelName(channel, true))
+				if guild, err := s.State.Cabinet.Guild(channel.GuildID); err == nil {
+					fmt.Fprintf(&buf, " (%s)", guild.Name)
+				}
+			} else {
+				buf.WriteString(ChannelName(channel, true))
+			}
+			buf.WriteByte('\n')
+		}
+	}
+
+	if len(aliases) > 0 {
+		if len(nicknames) > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString("Guilds:\n")
+		for _, guildID := range sortedKeys(aliases) {
+			fmt.Fprintf(&buf, "%s: ", aliases[guildID])
+			if guild, err := s.State.Cabinet.Guild(guildID); err == nil {
+				buf.WriteString(guild.Name)
+			} else {
+				buf.WriteString("left guild")
+			}
+			buf.WriteByte('\n')
+		}
+	}
+
+	return twicmd.TextResponse(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+func (s *Session) executeUnnick(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+	alias := args["alias"]
+
+	err := s.store.RemoveChannelNickname(ctx, alias)
+	if errors.Is(err, store.ErrNotFound) {
+		err = s.store.RemoveGuildAlias(ctx, alias)
+	}
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return twicmd.StatusResponse("no such alias")
+		}
+		return s.internalErrorResponse(req, err)
+	}
+
+	return twicmd.TextResponse(fmt.Sprintf("Removed alias %q.", alias))
+}
+
+func (s *Session) executeRenick(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+	oldAlias, newAlias := args["old"], args["new"]
+
+	if chID, err := s.store.ChannelFromNickname(ctx, oldAlias); err == nil {
+		if err := s.checkChannelNickname(ctx, newAlias, chID); err != nil {
+			return s.aliasErrorResponse(req, err)
+		}
+		if err := s.store.RenameChannelNickname(ctx, oldAlias, newAlias); err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+		return twicmd.TextResponse(fmt.Sprintf("Renamed alias %q to %q.", oldAlias, newAlias))
+	}
+
+	guildID, err := s.store.GuildFromAlias(ctx, oldAlias)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return twicmd.StatusResponse("no such alias")
+		}
+		return s.internalErrorResponse(req, err)
+	}
+
+	if err := s.checkGuildAlias(ctx, newAlias, guildID); err != nil {
+		return s.aliasErrorResponse(req, err)
+	}
+	if err := s.store.RenameGuildAlias(ctx, oldAlias, newAlias); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+	return twicmd.TextResponse(fmt.Sprintf("Renamed alias %q to %q.", oldAlias, newAlias))
+}
+
+func sortedKeys[K cmp.Ordered, V any](m map[K]V) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
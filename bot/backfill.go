@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+)
+
+const (
+	// backfillMaxMessages is the maximum number of missed messages that are
+	// forwarded after a reconnect. If more messages were missed, only a
+	// summary is sent.
+	backfillMaxMessages = 20
+	// backfillChannelLimit is the maximum number of messages fetched for each
+	// channel when backfilling.
+	backfillChannelLimit = 50
+	// backfillMaxAge is how far back messages are backfilled.
+	backfillMaxAge = 24 * time.Hour
+)
+
+type missedMessages struct {
+	Channel  discord.Channel
+	Messages []discord.Message // latest first
+}
+
+// setChannelCursor records the message as the last processed message in its
+// channel.
+func (s *Session) setChannelCursor(ctx context.Context, chID discord.ChannelID, msgID discord.MessageID) {
+	if err := s.store.SetChannelCursor(ctx, chID, msgID); err != nil {
+		s.logger.Error(
+			"failed to set channel cursor",
+			"channel_id", chID,
+			"message_id", msgID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+}
+
+// backfill forwards the notable messages that arrived while we were
+// disconnected. It only looks at unread DMs and channels with unread mentions.
+// Nothing is backfilled on the very first start, since nothing was missed.
+func (s *Session) backfill(ctx context.Context) {
+	since, err := s.store.LatestChannelCursor(ctx)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			s.logger.Error(
+				"failed to get latest channel cursor for backfilling",
+				"err", err,
+				*s.logAttrs.Load())
+		}
+		return
+	}
+
+	var channels []discord.Channel
+
+	dms, err := s.unreadPrivateChannels()
+	if err != nil {
+		s.logger.Error(
+			"failed to get unread DMs for backfilling",
+			"err", err,
+			*s.logAttrs.Load())
+	}
+	for _, dm := range dms {
+		channels = append(channels, dm.Channel)
+	}
+
+	guilds, _ := s.State.Cabinet.Guilds()
+	for _, guild := range guilds {
+		for _, ch := range s.mentionedGuildChannels(guild.ID) {
+			channels = append(channels, ch.Channel)
+		}
+	}
+
+	var missed []missedMessages
+	var total int
+
+	for _, ch := range channels {
+		if !s.shouldSend(ctx, ch.ID) {
+			continue
+		}
+		msgs := s.missedMessages(ctx, ch.ID, since)
+		if len(msgs) == 0 {
+			continue
+		}
+		missed = append(missed, missedMessages{
+			Channel:  ch,
+			Messages: msgs,
+		})
+		total += len(msgs)
+	}
+
+	if total == 0 {
+		return
+	}
+
+	s.logger.Info(
+		"backfilling messages missed while disconnected",
+		"count", total,
+		*s.logAttrs.Load())
+
+	// While the number is muted, the messages are queued like any other so
+	// that only the ones from VIP users get through.
+	if total > backfillMaxMessages && !s.store.NumberIsMuted(ctx) {
+		summary := outgoingSMS{Text: s.missedSummary(ctx, missed, total)}
+		for _, m := range missed {
+			summary.Forwarded = append(summary.Forwarded, s.forwardedMessages(m.Messages)...)
+			s.setChannelCursor(ctx, m.Channel.ID, m.Messages[0].ID)
+		}
+		s.sendOutgoingSMS(ctx, summary)
+		return
+	}
+
+	for _, m := range missed {
+		// Queue from earliest.
+		for i := len(m.Messages) - 1; i >= 0; i-- {
+			s.queueMessage(ctx, m.Channel.ID, m.Messages[i].ID, 5*time.Second)
+		}
+		s.setChannelCursor(ctx, m.Channel.ID, m.Messages[0].ID)
+	}
+}
+
+// missedMessages returns the notable messages in the channel that came after
+// since, the channel cursor and the user's last read message, latest first.
+func (s *Session) missedMessages(ctx context.Context, chID discord.ChannelID, since discord.MessageID) []discord.Message {
+	after := discord.MessageID(discord.NewSnowflake(time.Now().Add(-backfillMaxAge)))
+	if since > after {
+		after = since
+	}
+
+	if cursor, err := s.store.ChannelCursor(ctx, chID); err == nil && cursor > after {
+		after = cursor
+	}
+
+	if readState := s.State.ReadState.ReadState(chID); readState != nil && readState.LastMessageID > after {
+		after = readState.LastMessageID
+	}
+
+	msgs, err := s.State.MessagesAfter(chID, after, backfillChannelLimit)
+	if err != nil {
+		s.logger.Error(
+			"failed to fetch missed messages",
+			"channel_id", chID,
+			"err", err,
+			*s.logAttrs.Load())
+		return nil
+	}
+
+	return filterSlice(msgs, func(msg discord.Message) bool {
+		return s.isValidMessage(&msg) && s.forwardState(ctx, &msg) == notForwarded
+	})
+}
+
+// missedSummary summarizes the missed messages by channel.
+func (s *Session) missedSummary(ctx context.Context, missed []missedMessages, total int) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Missed %d messages in %d channels while disconnected:", total, len(missed))
+
+	for _, m := range missed {
+		guild, _ := s.State.Cabinet.Guild(m.Channel.GuildID)
+		fmt.Fprintf(&buf, "\n%s (%d)", s.channelHeader(ctx, &m.Channel, guild), len(m.Messages))
+	}
+
+	return buf.String()
+}
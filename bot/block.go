@@ -0,0 +1,315 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/ningen/v3"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+type blockRule struct {
+	store.BlockRule
+	userID discord.UserID
+	roleID discord.RoleID
+	re     *regexp.Regexp
+}
+
+func compileBlockRule(rule store.BlockRule) (blockRule, error) {
+	compiled := blockRule{BlockRule: rule}
+
+	switch rule.Kind {
+	case store.BlockUser:
+		sf, err := discord.ParseSnowflake(rule.Value)
+		if err != nil {
+			return compiled, errors.Wrap(err, "invalid user ID")
+		}
+		compiled.userID = discord.UserID(sf)
+	case store.BlockRole:
+		sf, err := discord.ParseSnowflake(rule.Value)
+		if err != nil {
+			return compiled, errors.Wrap(err, "invalid role ID")
+		}
+		compiled.roleID = discord.RoleID(sf)
+	case store.BlockContent:
+		re, err := regexp.Compile("(?i)" + rule.Value)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.re = re
+	default:
+		return compiled, fmt.Errorf("unknown block rule kind %q", rule.Kind)
+	}
+
+	return compiled, nil
+}
+
+// reloadBlockRules reloads the block rules from the store.
+func (s *Session) reloadBlockRules(ctx context.Context) {
+	rules, err := s.store.BlockRules(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load block rules",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	compiled := make([]blockRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileBlockRule(rule)
+		if err != nil {
+			s.logger.Warn(
+				"ignoring invalid block rule",
+				"rule_id", rule.ID,
+				"err", err,
+				*s.logAttrs.Load())
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.blockRules.Store(&compiled)
+}
+
+// isBlocked returns true if the message matches any of the user's block
+// rules for users or content. Role rules are checked by hasBlockedRole, since
+// they may need the author's member to be fetched.
+func (s *Session) isBlocked(msg *discord.Message) bool {
+	rules := s.blockRules.Load()
+	if rules == nil {
+		return false
+	}
+
+	for _, rule := range *rules {
+		switch rule.Kind {
+		case store.BlockUser:
+			if msg.Author.ID == rule.userID {
+				return true
+			}
+		case store.BlockContent:
+			if rule.re.MatchString(msg.Content) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasBlockedRole returns true if the author of the message has a role that the
+// user blocked in the message's guild.
+func (s *Session) hasBlockedRole(msg *discord.Message) bool {
+	rules := s.blockRules.Load()
+	if rules == nil || !msg.GuildID.IsValid() {
+		return false
+	}
+
+	var member *discord.Member
+	var memberFetched bool
+
+	for _, rule := range *rules {
+		if rule.Kind != store.BlockRole || msg.GuildID != rule.GuildID {
+			continue
+		}
+		if !memberFetched {
+			member = s.messageMember(msg)
+			memberFetched = true
+		}
+		if member != nil && slices.Contains(member.RoleIDs, rule.roleID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// messageMember returns the guild member that sent the message. The member
+// is looked up in the state first, which has it if it came with a message
+// event, and is fetched otherwise.
+func (s *Session) messageMember(msg *discord.Message) *discord.Member {
+	if member, err := s.State.Offline().Member(msg.GuildID, msg.Author.ID); err == nil {
+		return member
+	}
+
+	member, err := s.State.Member(msg.GuildID, msg.Author.ID)
+	if err != nil {
+		s.logger.Warn(
+			"failed to fetch message author's member",
+			"guild_id", msg.GuildID,
+			"user_id", msg.Author.ID,
+			"err", err,
+			*s.logAttrs.Load())
+		return nil
+	}
+
+	s.cacheMember(msg.GuildID, member)
+	return member
+}
+
+// cacheAuthor stores the member that came with a message event. Such members
+// come without their user, which is the message author.
+func (s *Session) cacheAuthor(msg *discord.Message, member *discord.Member) {
+	if member == nil {
+		return
+	}
+	member.User = msg.Author
+	s.cacheMember(msg.GuildID, member)
+}
+
+// cacheMember stores the member of a message author, since the state doesn't
+// keep it on its own.
+func (s *Session) cacheMember(guildID discord.GuildID, member *discord.Member) {
+	if !guildID.IsValid() || member == nil || !member.User.ID.IsValid() {
+		return
+	}
+	if err := s.State.Cabinet.MemberSet(guildID, member, true); err != nil {
+		s.logger.Warn(
+			"failed to cache message author's member",
+			"guild_id", guildID,
+			"user_id", member.User.ID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+}
+
+func (s *Session) executeBlock(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	return s.searchUserThen(ctx, args["user"], func(ctx context.Context, userID discord.UserID) *twicmdproto.ExecuteResponse {
+		return s.addBlockRule(ctx, req, store.BlockRule{
+			Kind:  store.BlockUser,
+			Value: userID.String(),
+		})
+	})
+}
+
+func (s *Session) executeBlockRole(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	guild, err := searchGuild(ctx, s.State, s.store, args["guild"])
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	role, err := searchRole(s.State, guild.ID, args["role"])
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	return s.addBlockRule(ctx, req, store.BlockRule{
+		Kind:    store.BlockRole,
+		Value:   role.ID.String(),
+		GuildID: guild.ID,
+	})
+}
+
+func (s *Session) executeBlockContent(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	pattern := strings.TrimSpace(args["pattern"])
+	if pattern == "" {
+		return twicmd.StatusResponse("you must specify a pattern")
+	}
+
+	return s.addBlockRule(ctx, req, store.BlockRule{
+		Kind:  store.BlockContent,
+		Value: pattern,
+	})
+}
+
+func (s *Session) addBlockRule(ctx context.Context, req *twicmdproto.ExecuteRequest, rule store.BlockRule) *twicmdproto.ExecuteResponse {
+	if _, err := compileBlockRule(rule); err != nil {
+		return twicmd.StatusResponse(fmt.Sprintf("invalid block rule: %v", err))
+	}
+
+	id, err := s.store.AddBlockRule(ctx, rule)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+	rule.ID = id
+
+	s.reloadBlockRules(ctx)
+
+	return twicmd.TextResponse("Added block rule " + s.blockRuleString(rule) + ".")
+}
+
+func (s *Session) executeUnblock(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	id, err := strconv.ParseInt(args["id"], 10, 64)
+	if err != nil {
+		return twicmd.StatusResponse("invalid block rule ID")
+	}
+
+	if err := s.store.RemoveBlockRule(ctx, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return twicmd.StatusResponse("no such block rule")
+		}
+		return s.internalErrorResponse(req, err)
+	}
+
+	s.reloadBlockRules(ctx)
+
+	return twicmd.TextResponse(fmt.Sprintf("Removed block rule %d.", id))
+}
+
+func (s *Session) executeBlocks(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	rules, err := s.store.BlockRules(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if len(rules) == 0 {
+		return twicmd.StatusResponse("No block rules.")
+	}
+
+	var buf strings.Builder
+	buf.WriteString("Block rules:\n")
+	for _, rule := range rules {
+		buf.WriteString(s.blockRuleString(rule))
+		buf.WriteByte('\n')
+	}
+	return twicmd.TextResponse(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// blockRuleString formats the block rule for displaying to the user.
+func (s *Session) blockRuleString(rule store.BlockRule) string {
+	return fmt.Sprintf("%d: %s %s", rule.ID, rule.Kind, BlockRuleValue(s.State, rule))
+}
+
+// BlockRuleValue returns the human-readable value of the block rule.
+func BlockRuleValue(state *ningen.State, rule store.BlockRule) string {
+	switch rule.Kind {
+	case store.BlockUser:
+		sf, err := discord.ParseSnowflake(rule.Value)
+		if err != nil {
+			return rule.Value
+		}
+		return UserName(state, discord.UserID(sf))
+	case store.BlockRole:
+		sf, err := discord.ParseSnowflake(rule.Value)
+		if err != nil {
+			return rule.Value
+		}
+		role, err := state.Cabinet.Role(rule.GuildID, discord.RoleID(sf))
+		if err != nil {
+			return rule.Value
+		}
+		name := "@" + role.Name
+		if guild, err := state.Cabinet.Guild(rule.GuildID); err == nil {
+			name += " (" + guild.Name + ")"
+		}
+		return name
+	default:
+		return "/" + rule.Value + "/"
+	}
+}
@@ -0,0 +1,468 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/proto/out/twismsproto"
+	"github.com/twipi/twipi/twicmd"
+	"golang.org/x/time/rate"
+)
+
+// heldRetryDelay is how long to wait before retrying to send held texts if
+// sending them failed.
+const heldRetryDelay = time.Minute
+
+// outgoingSMS is a text to be sent to the user.
+type outgoingSMS struct {
+	Text string
+	// Forwarded are the messages that the text forwards. They're only marked
+	// as forwarded and removed from the pending queue once the text is
+	// delivered, so that they're replayed after a restart otherwise.
+	Forwarded []store.ForwardedMessage
+}
+
+// sendSMS sends the given text to the user, subject to the account's SMS
+// budget and rate limit.
+func (s *Session) sendSMS(ctx context.Context, text string) {
+	s.sendOutgoingSMS(ctx, outgoingSMS{Text: text})
+}
+
+// sendOutgoingSMS is like sendSMS, but also keeps track of the messages that
+// the text forwards.
+func (s *Session) sendOutgoingSMS(ctx context.Context, sms outgoingSMS) {
+	budget := s.smsBudget(ctx)
+
+	until, reason := s.overBudget(ctx, budget, smsSegments(sms.Text))
+	if reason == "" {
+		s.deliverSMS(ctx, sms)
+		return
+	}
+
+	s.logger.Debug(
+		"SMS is over budget",
+		"reason", reason,
+		"until", until,
+		"action", budget.OverBudget,
+		*s.logAttrs.Load())
+
+	switch budget.OverBudget {
+	case store.OverBudgetDrop:
+		s.dropOverBudget(ctx, reason)
+		s.removePending(ctx, forwardedIDs(sms.Forwarded))
+	default:
+		s.holdOverBudget(ctx, budget, sms, until)
+	}
+}
+
+// deliverSMS sends the given text to the user right away and records its
+// usage. It returns false if the text couldn't be sent.
+func (s *Session) deliverSMS(ctx context.Context, sms outgoingSMS) bool {
+	message := &twismsproto.Message{
+		From: s.Account.ServerNumber,
+		To:   s.Account.UserNumber,
+		Body: &twismsproto.MessageBody{
+			Text: &twismsproto.TextBody{Text: sms.Text},
+		},
+	}
+
+	s.logger.Debug(
+		"sending SMS",
+		"from", message.From,
+		"to", message.To,
+		"body", sms.Text)
+
+	if err := s.sms.SendMessage(ctx, message); err != nil {
+		s.logger.Error(
+			"failed to send SMS",
+			"err", err,
+			*s.logAttrs.Load())
+		return false
+	}
+
+	s.budget.Lock()
+	s.budget.noticed = ""
+	s.budget.Unlock()
+
+	if err := s.store.AddSMSUsage(ctx, time.Now(), smsSegments(sms.Text)); err != nil {
+		s.logger.Warn(
+			"failed to record SMS usage",
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	s.markForwarded(ctx, sms.Forwarded)
+	s.removePending(ctx, forwardedIDs(sms.Forwarded))
+
+	return true
+}
+
+func (s *Session) smsBudget(ctx context.Context) store.SMSBudget {
+	budget, err := s.store.SMSBudget(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to get SMS budget, sending without one",
+			"err", err,
+			*s.logAttrs.Load())
+		return store.SMSBudget{}
+	}
+	return budget
+}
+
+// overBudget checks if sending the given number of segments would exceed the
+// budget. If it would, then the reason and the time at which the budget will
+// allow sending again are returned.
+func (s *Session) overBudget(ctx context.Context, budget store.SMSBudget, segments int) (time.Time, string) {
+	if left, until, reason := s.segmentsLeft(ctx, budget); left != -1 && segments > left {
+		return until, reason
+	}
+
+	if budget.HourlyMessages > 0 {
+		now := time.Now()
+		r := s.rateLimiter(budget.HourlyMessages).ReserveN(now, 1)
+		if delay := r.DelayFrom(now); delay > 0 {
+			r.CancelAt(now)
+			return now.Add(delay), "hourly SMS rate limit"
+		}
+	}
+
+	return time.Time{}, ""
+}
+
+// segmentsLeft returns the number of segments that the daily and monthly
+// budgets still allow, or -1 if there is no limit. If there is, then the time
+// at which more segments are allowed and the reason are also returned.
+func (s *Session) segmentsLeft(ctx context.Context, budget store.SMSBudget) (int, time.Time, string) {
+	// Days and months start at midnight where the user is.
+	now := time.Now().In(s.location(ctx))
+
+	left := -1
+	var until time.Time
+	var reason string
+
+	if budget.DailySegments > 0 {
+		day := startOfDay(now)
+		used, err := s.store.SMSUsage(ctx, day)
+		if err == nil {
+			left = max(budget.DailySegments-used, 0)
+			until, reason = day.AddDate(0, 0, 1), "daily SMS budget"
+		}
+	}
+
+	if budget.MonthlySegments > 0 {
+		month := startOfDay(now).AddDate(0, 0, 1-now.Day())
+		used, err := s.store.SMSUsage(ctx, month)
+		if err == nil && (left == -1 || budget.MonthlySegments-used < left) {
+			left = max(budget.MonthlySegments-used, 0)
+			until, reason = month.AddDate(0, 1, 0), "monthly SMS budget"
+		}
+	}
+
+	return left, until, reason
+}
+
+// maxBudgetSegments returns the most segments that a single text may ever
+// have under the budget, or 0 if there is no limit.
+func maxBudgetSegments(budget store.SMSBudget) int {
+	limit := budget.DailySegments
+	if budget.MonthlySegments > 0 && (limit == 0 || budget.MonthlySegments < limit) {
+		limit = budget.MonthlySegments
+	}
+	return limit
+}
+
+// rateLimiter returns the token bucket for the given number of messages per
+// hour.
+func (s *Session) rateLimiter(hourly int) *rate.Limiter {
+	s.budget.Lock()
+	defer s.budget.Unlock()
+
+	if s.budget.limiter == nil || s.budget.hourly != hourly {
+		s.budget.limiter = rate.NewLimiter(rate.Every(time.Hour/time.Duration(hourly)), hourly)
+		s.budget.hourly = hourly
+	}
+
+	return s.budget.limiter
+}
+
+func (s *Session) dropOverBudget(ctx context.Context, reason string) {
+	s.budget.Lock()
+	noticed := s.budget.noticed == reason
+	s.budget.noticed = reason
+	s.budget.Unlock()
+
+	if noticed {
+		return
+	}
+
+	s.deliverSMS(ctx, outgoingSMS{Text: fmt.Sprintf(
+		"Your %s was reached. Notifications will be dropped until it resets.",
+		reason)})
+
+	// deliverSMS resets the notice, so set it again.
+	s.budget.Lock()
+	s.budget.noticed = reason
+	s.budget.Unlock()
+}
+
+// holdOverBudget holds the text until the given time, when the budget allows
+// sending again. Texts that would never fit the budget are dropped instead.
+func (s *Session) holdOverBudget(ctx context.Context, budget store.SMSBudget, sms outgoingSMS, until time.Time) {
+	if limit := maxBudgetSegments(budget); limit > 0 && smsSegments(sms.Text) > limit {
+		s.dropTooLarge(ctx, sms)
+		return
+	}
+
+	if _, err := s.store.AddHeldSMS(ctx, store.HeldSMS{
+		Text:      sms.Text,
+		Forwarded: sms.Forwarded,
+	}); err != nil {
+		s.logger.Error(
+			"failed to hold SMS, dropping it",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	s.retryHeldSMS(ctx, until)
+}
+
+// retryHeldSMS tries to send the held texts at the given time, unless a retry
+// is already scheduled.
+func (s *Session) retryHeldSMS(ctx context.Context, at time.Time) {
+	s.budget.Lock()
+	defer s.budget.Unlock()
+
+	if s.budget.retry == nil {
+		s.budget.retry = time.AfterFunc(time.Until(at), func() {
+			s.flushHeldSMS(ctx)
+		})
+	}
+}
+
+// flushHeldSMS sends the texts that were held because of the budget as
+// digests that fit the budget. The texts that don't fit are held until the
+// budget allows sending again, and texts that would never fit are dropped.
+func (s *Session) flushHeldSMS(ctx context.Context) {
+	s.budget.flushing.Lock()
+	defer s.budget.flushing.Unlock()
+
+	s.budget.Lock()
+	s.budget.retry = nil
+	s.budget.Unlock()
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	held, err := s.store.HeldSMS(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load held SMS",
+			"err", err,
+			*s.logAttrs.Load())
+		s.retryHeldSMS(ctx, time.Now().Add(heldRetryDelay))
+		return
+	}
+
+	budget := s.smsBudget(ctx)
+
+	for len(held) > 0 {
+		// The budget may have been lowered since the text was held.
+		if limit := maxBudgetSegments(budget); limit > 0 && smsSegments(held[0].Text) > limit {
+			s.removeHeldSMS(ctx, held[:1])
+			s.dropTooLarge(ctx, outgoingSMS{
+				Text:      held[0].Text,
+				Forwarded: held[0].Forwarded,
+			})
+			held = held[1:]
+			continue
+		}
+
+		texts := make([]string, len(held))
+		for i, h := range held {
+			texts[i] = h.Text
+		}
+
+		n := len(texts)
+		left, until, _ := s.segmentsLeft(ctx, budget)
+		if left != -1 {
+			n = fitHeldSMS(texts, left)
+		}
+		if n == 0 {
+			s.retryHeldSMS(ctx, until)
+			return
+		}
+
+		digest := outgoingSMS{Text: heldSMSDigest(texts[:n])}
+		for _, h := range held[:n] {
+			digest.Forwarded = append(digest.Forwarded, h.Forwarded...)
+		}
+
+		if until, reason := s.overBudget(ctx, budget, smsSegments(digest.Text)); reason != "" {
+			s.retryHeldSMS(ctx, until)
+			return
+		}
+
+		if !s.deliverSMS(ctx, digest) {
+			s.retryHeldSMS(ctx, time.Now().Add(heldRetryDelay))
+			return
+		}
+
+		s.removeHeldSMS(ctx, held[:n])
+		held = held[n:]
+	}
+}
+
+func (s *Session) removeHeldSMS(ctx context.Context, held []store.HeldSMS) {
+	for _, h := range held {
+		if err := s.store.RemoveHeldSMS(ctx, h.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			s.logger.Error(
+				"failed to remove held SMS",
+				"id", h.ID,
+				"err", err,
+				*s.logAttrs.Load())
+		}
+	}
+}
+
+// removeHeldForwarding removes the held texts that forward any of the given
+// messages and returns them.
+func (s *Session) removeHeldForwarding(ctx context.Context, ids []discord.MessageID) []outgoingSMS {
+	s.budget.flushing.Lock()
+	defer s.budget.flushing.Unlock()
+
+	held, err := s.store.HeldSMS(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load held SMS",
+			"err", err,
+			*s.logAttrs.Load())
+		return nil
+	}
+
+	var removed []outgoingSMS
+	for _, h := range held {
+		if forwardsAny(h.Forwarded, ids) {
+			s.removeHeldSMS(ctx, []store.HeldSMS{h})
+			removed = append(removed, outgoingSMS{Text: h.Text, Forwarded: h.Forwarded})
+		}
+	}
+	return removed
+}
+
+// dropTooLarge drops the text because it's larger than the budget allows and
+// tells the user, even though the notice itself goes over budget.
+func (s *Session) dropTooLarge(ctx context.Context, sms outgoingSMS) {
+	s.removePending(ctx, forwardedIDs(sms.Forwarded))
+	s.deliverSMS(ctx, outgoingSMS{Text: fmt.Sprintf(
+		"A notification of %d segments was dropped because it's larger than your SMS budget: %s",
+		smsSegments(sms.Text), truncateText(sms.Text, 80))})
+}
+
+// fitHeldSMS returns the number of held texts, starting from the first, whose
+// digest fits in the given number of segments.
+func fitHeldSMS(held []string, segments int) int {
+	var n int
+	for n < len(held) && smsSegments(heldSMSDigest(held[:n+1])) <= segments {
+		n++
+	}
+	return n
+}
+
+// heldSMSDigest joins the held texts into a single text.
+func heldSMSDigest(held []string) string {
+	if len(held) == 1 {
+		return held[0]
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%d notifications were held because of your SMS budget:\n", len(held))
+	for _, text := range held {
+		buf.WriteString("\n")
+		buf.WriteString(text)
+		buf.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func (s *Session) executeBudget(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	budget, err := s.store.SMSBudget(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	words := strings.Fields(args["setting"])
+	if len(words) == 0 {
+		return twicmd.TextResponse(s.budgetString(ctx, budget))
+	}
+
+	switch words[0] {
+	case "off":
+		budget = store.SMSBudget{OverBudget: budget.OverBudget}
+	case "daily", "monthly", "rate":
+		if len(words) != 2 {
+			return twicmd.StatusResponse("usage: budget " + words[0] + " <number>")
+		}
+		n, err := strconv.Atoi(words[1])
+		if err != nil || n < 0 {
+			return twicmd.StatusResponse("invalid number " + strconv.Quote(words[1]))
+		}
+		switch words[0] {
+		case "daily":
+			budget.DailySegments = n
+		case "monthly":
+			budget.MonthlySegments = n
+		case "rate":
+			budget.HourlyMessages = n
+		}
+	case "hold", "drop":
+		budget.OverBudget = store.OverBudgetAction(words[0])
+	default:
+		return twicmd.StatusResponse("unknown budget setting " + strconv.Quote(words[0]))
+	}
+
+	if err := s.store.SetSMSBudget(ctx, budget); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	return twicmd.TextResponse(s.budgetString(ctx, budget))
+}
+
+// budgetString formats the SMS budget and its current usage for displaying to
+// the user.
+func (s *Session) budgetString(ctx context.Context, budget store.SMSBudget) string {
+	now := time.Now().In(s.location(ctx))
+	daily, _ := s.store.SMSUsage(ctx, startOfDay(now))
+	monthly, _ := s.store.SMSUsage(ctx, startOfDay(now).AddDate(0, 0, 1-now.Day()))
+
+	limit := func(n int) string {
+		if n == 0 {
+			return "unlimited"
+		}
+		return strconv.Itoa(n)
+	}
+
+	var buf strings.Builder
+	buf.WriteString("SMS budget:\n")
+	fmt.Fprintf(&buf, "Today: %d/%s segments\n", daily, limit(budget.DailySegments))
+	fmt.Fprintf(&buf, "This month: %d/%s segments\n", monthly, limit(budget.MonthlySegments))
+	fmt.Fprintf(&buf, "Rate limit: %s messages per hour\n", limit(budget.HourlyMessages))
+	fmt.Fprintf(&buf, "When over budget: %s", budget.OverBudget)
+	return buf.String()
+}
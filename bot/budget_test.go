@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSMSSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		segments int
+	}{
+		{"empty", "", 1},
+		{"short", "hello", 1},
+		{"gsm7 single", strings.Repeat("a", 160), 1},
+		{"gsm7 concatenated", strings.Repeat("a", 161), 2},
+		{"gsm7 three", strings.Repeat("a", 307), 3},
+		{"gsm7 extension", strings.Repeat("{", 80), 1},
+		{"gsm7 extension overflow", strings.Repeat("{", 81), 2},
+		{"ucs2 single", strings.Repeat("é", 10) + "→", 1},
+		{"ucs2 concatenated", strings.Repeat("→", 71), 2},
+		{"ucs2 surrogate pairs", strings.Repeat("😀", 35), 1},
+		{"ucs2 surrogate overflow", strings.Repeat("😀", 36), 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if segments := smsSegments(test.text); segments != test.segments {
+				t.Errorf("smsSegments() = %d, want %d", segments, test.segments)
+			}
+		})
+	}
+}
+
+func TestHeldSMSDigest(t *testing.T) {
+	tests := []struct {
+		name   string
+		held   []string
+		digest string
+	}{
+		{
+			name:   "single",
+			held:   []string{"alice: hi"},
+			digest: "alice: hi",
+		},
+		{
+			name: "multiple",
+			held: []string{"alice: hi", "bob: hey"},
+			digest: "2 notifications were held because of your SMS budget:\n" +
+				"\nalice: hi\n" +
+				"\nbob: hey",
+		},
+		{
+			name:   "long",
+			held:   []string{strings.Repeat("a", 1000)},
+			digest: strings.Repeat("a", 1000),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if digest := heldSMSDigest(test.held); digest != test.digest {
+				t.Errorf("heldSMSDigest() = %q, want %q", digest, test.digest)
+			}
+		})
+	}
+}
+
+func TestFitHeldSMS(t *testing.T) {
+	short := strings.Repeat("a", 50)
+	long := strings.Repeat("a", 300) // 2 segments
+
+	tests := []struct {
+		name     string
+		held     []string
+		segments int
+		n        int
+	}{
+		{"none left", []string{short}, 0, 0},
+		{"single fits", []string{short}, 1, 1},
+		{"single too long", []string{long}, 1, 0},
+		{"some fit", []string{short, short, long, short}, 2, 2},
+		{"all fit", []string{short, short, long, short}, 10, 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n := fitHeldSMS(test.held, test.segments)
+			if n != test.n {
+				t.Errorf("fitHeldSMS() = %d, want %d", n, test.n)
+			}
+			if n > 0 {
+				if segments := smsSegments(heldSMSDigest(test.held[:n])); segments > test.segments {
+					t.Errorf("digest of %d texts has %d segments, over %d", n, segments, test.segments)
+				}
+			}
+		})
+	}
+}
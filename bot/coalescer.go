@@ -0,0 +1,126 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+// smsCoalescer merges the per-channel sections that become ready within a
+// short window into a single SMS.
+type smsCoalescer struct {
+	ctx         context.Context
+	send        func(context.Context, outgoingSMS)
+	window      time.Duration
+	maxSegments int
+
+	mu       sync.Mutex
+	sections []outgoingSMS
+	timer    *time.Timer
+}
+
+// newSMSCoalescer creates a coalescer that sends using the given context until
+// it's closed.
+func newSMSCoalescer(ctx context.Context, window time.Duration, maxSegments int, send func(context.Context, outgoingSMS)) *smsCoalescer {
+	return &smsCoalescer{
+		ctx:         ctx,
+		send:        send,
+		window:      window,
+		maxSegments: maxSegments,
+	}
+}
+
+// Add adds a section to be sent. The section is sent along with all other
+// sections added within the window. If now is true, then the section and all
+// pending sections are sent right away.
+func (c *smsCoalescer) Add(section outgoingSMS, now bool) {
+	c.mu.Lock()
+	c.sections = append(c.sections, section)
+	if !now && c.timer == nil {
+		c.timer = time.AfterFunc(c.window, c.Flush)
+	}
+	c.mu.Unlock()
+
+	if now {
+		c.Flush()
+	}
+}
+
+// Remove removes the pending sections that forward any of the given messages
+// and returns them.
+func (c *smsCoalescer) Remove(ids []discord.MessageID) []outgoingSMS {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var removed []outgoingSMS
+	c.sections = slices.DeleteFunc(c.sections, func(section outgoingSMS) bool {
+		if forwardsAny(section.Forwarded, ids) {
+			removed = append(removed, section)
+			return true
+		}
+		return false
+	})
+	return removed
+}
+
+// Flush sends all pending sections right away.
+func (c *smsCoalescer) Flush() {
+	c.flush(c.ctx)
+}
+
+// Close sends all pending sections using the given context, since the
+// coalescer's own context is usually done by the time it's closed.
+func (c *smsCoalescer) Close(ctx context.Context) {
+	c.flush(ctx)
+}
+
+func (c *smsCoalescer) flush(ctx context.Context) {
+	c.mu.Lock()
+	sections := c.sections
+	c.sections = nil
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	c.mu.Unlock()
+
+	for _, sms := range c.merge(sections) {
+		c.send(ctx, sms)
+	}
+}
+
+// merge joins the sections into as few SMS as possible without exceeding the
+// maximum number of segments per SMS. A section that is too long on its own is
+// still sent as a single SMS.
+func (c *smsCoalescer) merge(sections []outgoingSMS) []outgoingSMS {
+	var merged []outgoingSMS
+	var current outgoingSMS
+
+	for _, section := range sections {
+		if current.Text == "" {
+			current = section
+			continue
+		}
+
+		text := current.Text + "\n\n" + section.Text
+		if smsSegments(text) > c.maxSegments {
+			merged = append(merged, current)
+			current = section
+			continue
+		}
+
+		current = outgoingSMS{
+			Text:      text,
+			Forwarded: slices.Concat(current.Forwarded, section.Forwarded),
+		}
+	}
+
+	if current.Text != "" {
+		merged = append(merged, current)
+	}
+
+	return merged
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/gateway"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+func (s *Session) onMessageDelete(ctx context.Context, ev *gateway.MessageDeleteEvent) {
+	s.handleDeletes(ctx, ev.ChannelID, []discord.MessageID{ev.ID})
+}
+
+func (s *Session) onMessageDeleteBulk(ctx context.Context, ev *gateway.MessageDeleteBulkEvent) {
+	s.handleDeletes(ctx, ev.ChannelID, ev.IDs)
+}
+
+// handleDeletes makes sure that the deleted messages are never sent, and
+// notifies the user about the ones that were already forwarded if they asked
+// for it.
+func (s *Session) handleDeletes(ctx context.Context, chID discord.ChannelID, ids []discord.MessageID) {
+	if throttler, ok := s.throttlers.throttlers.Load(chID); ok {
+		for _, id := range ids {
+			throttler.RemoveMessage(id)
+		}
+	}
+	s.removePending(ctx, ids)
+
+	// Texts that weren't delivered yet are rendered again without the deleted
+	// messages.
+	undelivered := slices.Concat(s.coalescer.Remove(ids), s.removeHeldForwarding(ctx, ids))
+	s.resendUndelivered(ctx, undelivered, ids)
+
+	var deleted []store.ForwardedMessage
+	for _, id := range ids {
+		// Messages still waiting for the digest were never delivered.
+		if s.removeFromDigest(ctx, id) {
+			continue
+		}
+
+		forwarded, err := s.store.ForwardedMessage(ctx, id)
+		if err != nil {
+			if !errors.Is(err, store.ErrNotFound) {
+				s.logger.Error(
+					"failed to check if deleted message was forwarded",
+					"message_id", id,
+					"err", err,
+					*s.logAttrs.Load())
+			}
+			continue
+		}
+		deleted = append(deleted, forwarded)
+	}
+
+	if len(deleted) == 0 || !s.deliverySettings(ctx).NotifyDeletes {
+		return
+	}
+
+	if s.store.NumberIsMuted(ctx) || !s.shouldSend(ctx, chID) {
+		return
+	}
+
+	name := chID.Mention()
+	if channel, err := s.State.Cabinet.Channel(chID); err == nil {
+		guild, _ := s.State.Cabinet.Guild(channel.GuildID)
+		name = s.channelHeader(ctx, channel, guild)
+	}
+
+	var notice string
+	if len(deleted) == 1 {
+		author := deleted[0].Author
+		if author == "" {
+			author = "someone"
+		}
+		notice = fmt.Sprintf("%s deleted a message in %s", author, name)
+	} else {
+		notice = fmt.Sprintf("%d forwarded messages were deleted in %s", len(deleted), name)
+	}
+
+	s.coalescer.Add(outgoingSMS{Text: notice}, false)
+}
+
+// resendUndelivered sends the messages that the undelivered texts forward
+// again, except for the deleted ones.
+func (s *Session) resendUndelivered(ctx context.Context, undelivered []outgoingSMS, deleted []discord.MessageID) {
+	channelIDs := make(map[discord.ChannelID][]discord.MessageID)
+	for _, sms := range undelivered {
+		for _, msg := range sms.Forwarded {
+			if !slices.Contains(deleted, msg.MessageID) {
+				channelIDs[msg.ChannelID] = append(channelIDs[msg.ChannelID], msg.MessageID)
+			}
+		}
+	}
+
+	for chID, ids := range channelIDs {
+		// sendMessageIDs starts from the first ID.
+		slices.Sort(ids)
+		s.sendMessageIDs(ctx, chID, ids)
+	}
+}
+
+// forwardsAny returns whether any of the forwarded messages has one of the
+// given IDs.
+func forwardsAny(forwarded []store.ForwardedMessage, ids []discord.MessageID) bool {
+	return slices.ContainsFunc(forwarded, func(msg store.ForwardedMessage) bool {
+		return slices.Contains(ids, msg.MessageID)
+	})
+}
+
+func (s *Session) executeDeletes(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	settings, err := s.store.DeliverySettings(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	switch args["setting"] {
+	case "":
+		if settings.NotifyDeletes {
+			return twicmd.TextResponse("You are notified when a forwarded message is deleted.")
+		}
+		return twicmd.TextResponse("You are not notified when a forwarded message is deleted.")
+	case "on":
+		settings.NotifyDeletes = true
+	case "off":
+		settings.NotifyDeletes = false
+	default:
+		return twicmd.StatusResponse("usage: deletes [on|off]")
+	}
+
+	if err := s.store.SetDeliverySettings(ctx, settings); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if settings.NotifyDeletes {
+		return twicmd.TextResponse("You will be notified when a forwarded message is deleted.")
+	}
+	return twicmd.TextResponse("You will no longer be notified when a forwarded message is deleted.")
+}
@@ -0,0 +1,391 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+const (
+	// defaultDigestInterval is the digest interval used when digests are
+	// turned on without one.
+	defaultDigestInterval = time.Hour
+	// digestLinesPerChannel is the number of latest messages shown for each
+	// channel in a digest.
+	digestLinesPerChannel = 2
+)
+
+func (s *Session) deliverySettings(ctx context.Context) store.DeliverySettings {
+	settings, err := s.store.DeliverySettings(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to get delivery settings, using realtime delivery",
+			"err", err,
+			*s.logAttrs.Load())
+		return store.DeliverySettings{Mode: store.DeliveryRealtime}
+	}
+	return settings
+}
+
+// addToDigest stores the given messages to be sent in the next digest
+// instead of sending them right away.
+func (s *Session) addToDigest(ctx context.Context, channel *discord.Channel, msgs []discord.Message, edited map[discord.MessageID]bool) {
+	s.digest.Lock()
+	defer s.digest.Unlock()
+
+	// Iterate from earliest.
+	for i := len(msgs) - 1; i >= 0; i-- {
+		msg := &msgs[i]
+		entry := store.DigestEntry{
+			ChannelID: channel.ID,
+			MessageID: msg.ID,
+			Author:    s.authorName(msg),
+			Content:   s.renderMessage(msg),
+		}
+
+		// Replace the entry if the message is already waiting for this
+		// digest, otherwise mark it as an edit of a delivered message.
+		ix := slices.IndexFunc(s.digest.entries, func(e store.DigestEntry) bool {
+			return e.MessageID == msg.ID
+		})
+		if ix != -1 {
+			s.digest.entries[ix] = entry
+		} else {
+			if edited[msg.ID] {
+				entry.Content = "(edited) " + entry.Content
+			}
+			s.digest.entries = append(s.digest.entries, entry)
+		}
+
+		// Persist the entry so that it survives a restart.
+		if err := s.store.AddDigestEntry(ctx, entry); err != nil {
+			s.logger.Error(
+				"failed to persist digest entry",
+				"message_id", msg.ID,
+				"err", err,
+				*s.logAttrs.Load())
+		}
+	}
+}
+
+// removeFromDigest removes the message from the next digest. It returns true
+// if the message was waiting for the digest.
+func (s *Session) removeFromDigest(ctx context.Context, msgID discord.MessageID) bool {
+	s.digest.Lock()
+	defer s.digest.Unlock()
+
+	ix := slices.IndexFunc(s.digest.entries, func(e store.DigestEntry) bool {
+		return e.MessageID == msgID
+	})
+	if ix == -1 {
+		return false
+	}
+	s.digest.entries = slices.Delete(s.digest.entries, ix, ix+1)
+
+	if err := s.store.RemoveDigestEntry(ctx, msgID); err != nil {
+		s.logger.Error(
+			"failed to remove digest entry",
+			"message_id", msgID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	return true
+}
+
+// restoreDigest loads the digest entries persisted before the last restart.
+func (s *Session) restoreDigest(ctx context.Context) {
+	entries, err := s.store.DigestEntries(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to restore digest entries",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	s.digest.Lock()
+	s.digest.entries = entries
+	s.digest.last = time.Now()
+	s.digest.Unlock()
+}
+
+// runDigests sends digests according to the delivery settings until ctx is
+// canceled.
+func (s *Session) runDigests(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.digest.Lock()
+			last := s.digest.last
+			s.digest.Unlock()
+
+			// Hold the digest while the number is muted. It's sent once the
+			// number is unmuted, since it's still due then.
+			now = now.In(s.location(ctx))
+			if digestDue(s.deliverySettings(ctx), last, now) && !s.store.NumberIsMuted(ctx) {
+				s.sendDigest(ctx)
+			}
+		}
+	}
+}
+
+// digestDue returns true if a digest should be sent at now, given that the
+// last one was sent at last. Digest times are in the time zone of now.
+func digestDue(settings store.DeliverySettings, last, now time.Time) bool {
+	if settings.Mode != store.DeliveryDigest {
+		return false
+	}
+
+	if len(settings.DigestTimes) > 0 {
+		for _, timeOfDay := range settings.DigestTimes {
+			t, err := time.Parse("15:04", timeOfDay)
+			if err != nil {
+				continue
+			}
+			y, m, d := now.Date()
+			at := time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, now.Location())
+			if last.Before(at) && !now.Before(at) {
+				return true
+			}
+		}
+		return false
+	}
+
+	interval := settings.DigestInterval
+	if interval <= 0 {
+		interval = defaultDigestInterval
+	}
+	return now.Sub(last) >= interval
+}
+
+// sendDigest sends all messages collected for the digest right away.
+func (s *Session) sendDigest(ctx context.Context) {
+	s.digest.Lock()
+	entries := s.digest.entries
+	s.digest.entries = nil
+	s.digest.last = time.Now()
+	s.digest.Unlock()
+
+	if len(entries) == 0 {
+		return
+	}
+
+	if err := s.store.ClearDigestEntries(ctx); err != nil {
+		s.logger.Error(
+			"failed to clear persisted digest entries",
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	s.sendSMS(ctx, s.digestText(ctx, entries))
+}
+
+func (s *Session) digestText(ctx context.Context, entries []store.DigestEntry) string {
+	var channelIDs []discord.ChannelID
+	byChannel := make(map[discord.ChannelID][]store.DigestEntry)
+	for _, entry := range entries {
+		if _, ok := byChannel[entry.ChannelID]; !ok {
+			channelIDs = append(channelIDs, entry.ChannelID)
+		}
+		byChannel[entry.ChannelID] = append(byChannel[entry.ChannelID], entry)
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Digest: %d messages in %d channels\n", len(entries), len(channelIDs))
+
+	for _, chID := range channelIDs {
+		entries := byChannel[chID]
+
+		name := chID.Mention()
+		if channel, err := s.State.Cabinet.Channel(chID); err == nil {
+			guild, _ := s.State.Cabinet.Guild(channel.GuildID)
+			name = s.channelHeader(ctx, channel, guild)
+		}
+
+		fmt.Fprintf(&buf, "\n%s (%d):\n", name, len(entries))
+
+		if len(entries) > digestLinesPerChannel {
+			entries = entries[len(entries)-digestLinesPerChannel:]
+		}
+		for _, entry := range entries {
+			content := strings.Join(strings.Fields(entry.Content), " ")
+			fmt.Fprintf(&buf, "%s: %s\n", entry.Author, truncateText(content, 80))
+		}
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func (s *Session) executeDigest(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	settings, err := s.store.DeliverySettings(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	words := strings.Fields(args["setting"])
+	if len(words) == 0 {
+		s.digest.Lock()
+		pending := len(s.digest.entries)
+		s.digest.Unlock()
+
+		response := DeliverySettingsString(settings) + "."
+		if pending > 0 {
+			response += fmt.Sprintf(" %d messages are waiting for the next digest.", pending)
+		}
+		return twicmd.TextResponse(response)
+	}
+
+	if words[0] == "now" {
+		s.sendDigest(ctx)
+		return nil
+	}
+
+	settings, err = updateDigestSettings(settings, words)
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	if err := s.store.SetDeliverySettings(ctx, settings); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if settings.Mode != store.DeliveryDigest {
+		// Don't leave anything behind once digests are turned off.
+		s.sendDigest(ctx)
+	}
+
+	return twicmd.TextResponse(DeliverySettingsString(settings) + ".")
+}
+
+// updateDigestSettings applies the words given to the digest command, other
+// than "now", to the delivery settings.
+func updateDigestSettings(settings store.DeliverySettings, words []string) (store.DeliverySettings, error) {
+	switch words[0] {
+	case "on":
+		settings.Mode = store.DeliveryDigest
+		if settings.DigestInterval == 0 && len(settings.DigestTimes) == 0 {
+			settings.DigestInterval = defaultDigestInterval
+		}
+	case "off":
+		settings.Mode = store.DeliveryRealtime
+	case "every":
+		if len(words) != 2 {
+			return settings, errors.New("usage: digest every <duration>")
+		}
+		interval, err := str2duration.ParseDuration(words[1])
+		if err != nil {
+			return settings, errors.New("failed to parse duration")
+		}
+		if interval < time.Minute {
+			return settings, errors.New("the digest interval must be at least a minute")
+		}
+		settings.Mode = store.DeliveryDigest
+		settings.DigestInterval = interval
+		settings.DigestTimes = nil
+	case "at":
+		if len(words) < 2 {
+			return settings, errors.New("usage: digest at <time> [time...]")
+		}
+		times := make([]string, 0, len(words)-1)
+		for _, word := range words[1:] {
+			t, err := time.Parse("15:04", word)
+			if err != nil {
+				return settings, fmt.Errorf("invalid time %q, use 24-hour HH:MM", word)
+			}
+			times = append(times, t.Format("15:04"))
+		}
+		settings.Mode = store.DeliveryDigest
+		settings.DigestTimes = times
+	default:
+		return settings, fmt.Errorf("unknown digest setting %q", words[0])
+	}
+
+	return settings, nil
+}
+
+// SetDeliveryMode sets how notifications are delivered from a value formatted
+// like DeliveryModeValue. Messages waiting for a digest are sent right away if
+// digests are turned off.
+func (s *Session) SetDeliveryMode(ctx context.Context, value string) error {
+	settings, err := s.store.DeliverySettings(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get delivery settings")
+	}
+
+	words := strings.Fields(value)
+	switch {
+	case len(words) == 1 && words[0] == string(store.DeliveryRealtime):
+		settings, err = updateDigestSettings(settings, []string{"off"})
+	case len(words) == 1 && words[0] == string(store.DeliveryDigest):
+		settings, err = updateDigestSettings(settings, []string{"on"})
+	case len(words) > 1 && words[0] == string(store.DeliveryDigest):
+		settings, err = updateDigestSettings(settings, words[1:])
+	default:
+		err = fmt.Errorf("invalid delivery mode %q, use realtime, digest every <duration> or digest at <time> [time...]", value)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.store.SetDeliverySettings(ctx, settings); err != nil {
+		return errors.Wrap(err, "failed to set delivery settings")
+	}
+
+	if settings.Mode != store.DeliveryDigest {
+		s.sendDigest(ctx)
+	}
+
+	return nil
+}
+
+// DeliveryModeValue formats the delivery settings the way SetDeliveryMode
+// takes them, e.g. "digest every 1h".
+func DeliveryModeValue(settings store.DeliverySettings) string {
+	if settings.Mode != store.DeliveryDigest {
+		return string(store.DeliveryRealtime)
+	}
+
+	if len(settings.DigestTimes) > 0 {
+		return "digest at " + strings.Join(settings.DigestTimes, " ")
+	}
+
+	interval := settings.DigestInterval
+	if interval <= 0 {
+		interval = defaultDigestInterval
+	}
+	return "digest every " + str2duration.String(interval)
+}
+
+// DeliverySettingsString describes the delivery settings in a sentence.
+func DeliverySettingsString(settings store.DeliverySettings) string {
+	if settings.Mode != store.DeliveryDigest {
+		return "Messages are sent as they come in"
+	}
+
+	if len(settings.DigestTimes) > 0 {
+		return "Messages are sent as a digest at " + strings.Join(settings.DigestTimes, ", ")
+	}
+
+	interval := settings.DigestInterval
+	if interval <= 0 {
+		interval = defaultDigestInterval
+	}
+	return "Messages are sent as a digest every " + str2duration.String(interval)
+}
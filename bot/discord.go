@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -14,14 +16,25 @@ import (
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/arikawa/v3/utils/ws"
 	"github.com/diamondburned/ningen/v3"
-	"github.com/twipi/twipi/proto/out/twismsproto"
+	"github.com/twipi/twidiscord/store"
 )
 
-func (s *Session) bindDiscord() {
-	s.State.AddHandler(s.onMessageCreate)
-	s.State.AddHandler(s.onMessageUpdate)
+func (s *Session) bindDiscord(ctx context.Context) {
+	s.State.AddHandler(func(ev *gateway.MessageCreateEvent) {
+		s.onMessageCreate(ctx, ev)
+	})
+	s.State.AddHandler(func(ev *gateway.MessageUpdateEvent) {
+		s.onMessageUpdate(ctx, ev)
+	})
+	s.State.AddHandler(func(ev *gateway.MessageDeleteEvent) {
+		s.onMessageDelete(ctx, ev)
+	})
+	s.State.AddHandler(func(ev *gateway.MessageDeleteBulkEvent) {
+		s.onMessageDeleteBulk(ctx, ev)
+	})
 	s.State.AddHandler(s.onTypingStart)
 
+	var replayOnce sync.Once
 	s.State.AddHandler(func(r *gateway.ReadyEvent) {
 		me, _ := s.State.Me()
 
@@ -39,6 +52,14 @@ func (s *Session) bindDiscord() {
 		s.logger.Info(
 			"connected to Discord",
 			*s.logAttrs.Load())
+
+		// Replay the messages that were still queued when we last stopped,
+		// now that the state is ready to resolve them.
+		replayOnce.Do(func() { s.replayPending(ctx) })
+
+		// A new Ready event means that the gateway couldn't resume, so any
+		// messages sent in between were never dispatched to us.
+		go s.backfill(ctx)
 	})
 
 	s.State.AddHandler(func(ev *ws.CloseEvent) {
@@ -118,8 +139,24 @@ func (s *Session) isValidMessage(msg *discord.Message) bool {
 		return false
 	}
 
-	// Ignore messages sent by the current user or a bot.
-	if msg.Author.ID == me.ID || msg.Author.Bot {
+	// Ignore messages sent by the current user.
+	if msg.Author.ID == me.ID {
+		return false
+	}
+
+	// Ignore messages that the user has blocked.
+	if s.isBlocked(msg) {
+		return false
+	}
+
+	// Guild messages matching one of our watch rules are always sent, even if
+	// they're posted by a bot, since that's where most alerts come from.
+	if msg.GuildID.IsValid() && s.matchesWatchRule(msg) {
+		return !s.hasBlockedRole(msg)
+	}
+
+	// Ignore messages sent by a bot.
+	if msg.Author.Bot {
 		return false
 	}
 
@@ -129,16 +166,38 @@ func (s *Session) isValidMessage(msg *discord.Message) bool {
 		return false
 	}
 
-	return true
+	// Role rules are checked last, since they may need a request to Discord.
+	return !s.hasBlockedRole(msg)
 }
 
-func (s *Session) onMessageCreate(ev *gateway.MessageCreateEvent) {
+func (s *Session) onMessageCreate(ctx context.Context, ev *gateway.MessageCreateEvent) {
+	s.cacheAuthor(&ev.Message, ev.Member)
+
 	if !s.isValidChannel(ev.ChannelID) || !s.isValidMessage(&ev.Message) {
 		return
 	}
 
-	throttler := s.throttlers.forChannel(ev.ChannelID)
-	throttler.AddMessage(ev.ID, 5*time.Second)
+	s.setChannelCursor(ctx, ev.ChannelID, ev.ID)
+
+	if s.isVIP(ev.Author.ID) || s.focusedChannel() == ev.ChannelID {
+		s.queueMessage(ctx, ev.ChannelID, ev.ID, 0)
+
+		s.logger.With(*s.logAttrs.Load()).Debug(
+			"sending message from VIP user or focused channel immediately",
+			"channel_id", ev.ChannelID,
+			"message_id", ev.ID)
+		return
+	}
+
+	if s.deferUnfocused(ctx, ev.ChannelID, ev.ID) {
+		s.logger.With(*s.logAttrs.Load()).Debug(
+			"deferred message until focus mode ends",
+			"channel_id", ev.ChannelID,
+			"message_id", ev.ID)
+		return
+	}
+
+	s.queueMessage(ctx, ev.ChannelID, ev.ID, 5*time.Second)
 
 	s.logger.With(*s.logAttrs.Load()).Debug(
 		"queued message for sending",
@@ -146,7 +205,9 @@ func (s *Session) onMessageCreate(ev *gateway.MessageCreateEvent) {
 		"message_id", ev.ID)
 }
 
-func (s *Session) onMessageUpdate(ev *gateway.MessageUpdateEvent) {
+func (s *Session) onMessageUpdate(ctx context.Context, ev *gateway.MessageUpdateEvent) {
+	s.cacheAuthor(&ev.Message, ev.Member)
+
 	if !s.isValidChannel(ev.ChannelID) {
 		return
 	}
@@ -156,8 +217,7 @@ func (s *Session) onMessageUpdate(ev *gateway.MessageUpdateEvent) {
 		return
 	}
 
-	throttler := s.throttlers.forChannel(ev.ChannelID)
-	throttler.AddMessage(ev.ID, 5*time.Second)
+	s.queueMessage(ctx, ev.ChannelID, ev.ID, 5*time.Second)
 
 	s.logger.With(*s.logAttrs.Load()).Debug(
 		"queued updated message for sending",
@@ -188,12 +248,6 @@ func (s *Session) shouldSend(ctx context.Context, chID discord.ChannelID) bool {
 		return false
 	}
 
-	if s.store.NumberIsMuted(ctx) {
-		logger.Debug(
-			"skipping sending messages because the number is muted")
-		return false
-	}
-
 	if !s.isValidChannel(chID) {
 		logger.Debug(
 			"skipping sending messages because the channel is muted",
@@ -204,7 +258,10 @@ func (s *Session) shouldSend(ctx context.Context, chID discord.ChannelID) bool {
 	return true
 }
 
-func (s *Session) sendMessageIDs(ctx context.Context, chID discord.ChannelID, ids []discord.MessageID) {
+// sendMessageIDs sends the messages in the channel starting from the earliest
+// of the given IDs. It returns the IDs of the messages whose SMS is still to be
+// delivered.
+func (s *Session) sendMessageIDs(ctx context.Context, chID discord.ChannelID, ids []discord.MessageID) []discord.MessageID {
 	logger := s.logger.
 		With(*s.logAttrs.Load()).
 		With(
@@ -213,11 +270,11 @@ func (s *Session) sendMessageIDs(ctx context.Context, chID discord.ChannelID, id
 
 	if len(ids) == 0 {
 		logger.Debug("sending messages but there are no messages")
-		return
+		return nil
 	}
 
 	if !s.shouldSend(ctx, chID) {
-		return
+		return nil
 	}
 
 	channel, err := s.State.Cabinet.Channel(chID)
@@ -225,7 +282,7 @@ func (s *Session) sendMessageIDs(ctx context.Context, chID discord.ChannelID, id
 		logger.Error(
 			"failed to get channel for sending",
 			"err", err)
-		return
+		return nil
 	}
 
 	guild, err := s.State.Cabinet.Guild(channel.GuildID)
@@ -233,7 +290,7 @@ func (s *Session) sendMessageIDs(ctx context.Context, chID discord.ChannelID, id
 		logger.Error(
 			"failed to get guild for sending",
 			"err", err)
-		return
+		return nil
 	}
 
 	// Ignore all of our efforts in keeping track of a list of IDs. We'll
@@ -245,30 +302,56 @@ func (s *Session) sendMessageIDs(ctx context.Context, chID discord.ChannelID, id
 		logger.Error(
 			"failed to get messages for sending",
 			"err", err)
-		return
+		return nil
 	}
 
+	// Never re-send messages that were already delivered. Edited ones are
+	// sent again only if their content meaningfully changed.
+	edited := make(map[discord.MessageID]bool)
 	msgs = filterSlice(msgs, func(msg discord.Message) bool {
-		return msg.ID >= earliest && s.isValidMessage(&msg)
+		if msg.ID < earliest || !s.isValidMessage(&msg) {
+			return false
+		}
+		switch s.forwardState(ctx, &msg) {
+		case forwardedUnchanged:
+			return false
+		case forwardedEdited:
+			edited[msg.ID] = true
+		}
+		return true
 	})
 	if len(msgs) == 0 {
 		logger.Debug(
 			"skipping sending messages because there are no valid messages")
-		return
+		return nil
 	}
 
-	var name string
-	if nick, err := s.store.ChannelNickname(ctx, chID); err == nil {
-		name = nick
-	} else {
-		name = ChannelName(channel, true)
-		if guild != nil {
-			name = fmt.Sprintf("%s in %s", name, guild.Name)
+	if s.store.NumberIsMuted(ctx) {
+		// Messages from VIP users bypass the mute.
+		msgs = filterSlice(msgs, func(msg discord.Message) bool {
+			return s.isVIP(msg.Author.ID)
+		})
+		if len(msgs) == 0 {
+			logger.Debug(
+				"skipping sending messages because the number is muted")
+			return nil
 		}
 	}
 
+	// Messages from VIP users also skip waiting for other channels.
+	hasVIP := slices.ContainsFunc(msgs, func(msg discord.Message) bool {
+		return s.isVIP(msg.Author.ID)
+	})
+
+	if !hasVIP && s.deliverySettings(ctx).Mode == store.DeliveryDigest {
+		s.addToDigest(ctx, channel, msgs, edited)
+		s.markForwarded(ctx, s.forwardedMessages(msgs))
+		logger.Debug("added messages to digest")
+		return nil
+	}
+
 	var body strings.Builder
-	fmt.Fprintf(&body, "%s:\n", name)
+	fmt.Fprintf(&body, "%s:\n", s.channelHeader(ctx, channel, guild))
 
 	var lastAuthor discord.UserID
 
@@ -279,52 +362,94 @@ func (s *Session) sendMessageIDs(ctx context.Context, chID discord.ChannelID, id
 		// Only write the message author if it's different from the last one or
 		// and we're not in a DM.
 		if len(channel.DMRecipients) > 0 && lastAuthor != msg.Author.ID {
-			fmt.Fprintf(&body, "%s:\n", msg.Author.DisplayOrUsername())
+			fmt.Fprintf(&body, "%s:\n", s.authorName(msg))
 		}
 
-		content := renderText(logger, s.State, msg.Content, msg)
-		body.WriteString(content)
-
-		if len(msg.Embeds) > 0 {
-			body.WriteString("\n[embed]")
+		if edited[msg.ID] {
+			body.WriteString("(edited) ")
 		}
+		body.WriteString(s.renderMessage(msg))
+		body.WriteByte('\n')
+	}
 
-		if len(msg.Attachments) > 0 {
-			if len(msg.Attachments) == 1 {
-				fmt.Fprintf(&body, "\n[attached %s]", msg.Attachments[0].Filename)
-			} else {
-				fmt.Fprintf(&body, "\n[attached %d files]", len(msg.Attachments))
-			}
-		}
+	bodyFinal := strings.TrimSuffix(body.String(), "\n")
+	forwarded := s.forwardedMessages(msgs)
+	s.coalescer.Add(outgoingSMS{Text: bodyFinal, Forwarded: forwarded}, hasVIP)
+	return forwardedIDs(forwarded)
+}
+
+// channelHeader returns the name of the channel as shown above its messages.
+func (s *Session) channelHeader(ctx context.Context, channel *discord.Channel, guild *discord.Guild) string {
+	if nick, err := s.store.ChannelNickname(ctx, channel.ID); err == nil {
+		return nick
+	}
 
-		if msg.EditedTimestamp.IsValid() {
-			body.WriteString("*")
+	name := ChannelName(channel, true)
+
+	// Show threads and forum posts under their parent channel.
+	if isThread(channel) {
+		if parent, err := s.State.Cabinet.Channel(channel.ParentID); err == nil {
+			name = fmt.Sprintf("%s in #%s", name, parent.Name)
+			if guild != nil {
+				name = fmt.Sprintf("%s (%s)", name, s.guildName(ctx, guild))
+			}
+			return name
 		}
+	}
 
-		body.WriteByte('\n')
+	if guild != nil {
+		name = fmt.Sprintf("%s in %s", name, s.guildName(ctx, guild))
 	}
+	return name
+}
 
-	bodyFinal := strings.TrimSuffix(body.String(), "\n")
+// guildName returns the user's alias of the guild, or its name if it has none.
+func (s *Session) guildName(ctx context.Context, guild *discord.Guild) string {
+	if alias, err := s.store.GuildAlias(ctx, guild.ID); err == nil {
+		return alias
+	}
+	return guild.Name
+}
 
-	message := &twismsproto.Message{
-		From: s.Account.ServerNumber,
-		To:   s.Account.UserNumber,
-		Body: &twismsproto.MessageBody{
-			Text: &twismsproto.TextBody{Text: bodyFinal},
-		},
+// authorName returns the name that the message author goes by, which is their
+// guild nickname if they have one.
+func (s *Session) authorName(msg *discord.Message) string {
+	if msg.GuildID.IsValid() {
+		if member, err := s.State.Cabinet.Member(msg.GuildID, msg.Author.ID); err == nil && member.Nick != "" {
+			return member.Nick
+		}
 	}
 
-	s.logger.Debug(
-		"sending SMS",
-		"from", message.From,
-		"to", message.To,
-		"body", bodyFinal)
+	return msg.Author.DisplayOrUsername()
+}
 
-	if err := s.sms.SendMessage(ctx, message); err != nil {
-		logger.Error(
-			"failed to send SMS",
-			"err", err)
+// renderMessage renders the message's content into plain text, including
+// markers for its embeds and attachments.
+func (s *Session) renderMessage(msg *discord.Message) string {
+	var body strings.Builder
+	body.WriteString(renderText(s.logger, s.State, msg.Content, msg))
+
+	for _, embed := range msg.Embeds {
+		// Bots often post only embeds, so include enough to be useful.
+		switch {
+		case embed.Title != "":
+			fmt.Fprintf(&body, "\n[embed: %s]", embed.Title)
+		case embed.Description != "":
+			fmt.Fprintf(&body, "\n[embed: %s]", truncateText(embed.Description, 80))
+		default:
+			body.WriteString("\n[embed]")
+		}
 	}
+
+	if len(msg.Attachments) > 0 {
+		if len(msg.Attachments) == 1 {
+			fmt.Fprintf(&body, "\n[attached %s]", msg.Attachments[0].Filename)
+		} else {
+			fmt.Fprintf(&body, "\n[attached %d files]", len(msg.Attachments))
+		}
+	}
+
+	return body.String()
 }
 
 func filterSlice[T any](slice []T, filter func(T) bool) []T {
@@ -337,6 +462,25 @@ func filterSlice[T any](slice []T, filter func(T) bool) []T {
 	return filtered
 }
 
+// UserName returns the display name of the user with the given ID if it's
+// known, or the user ID otherwise.
+func UserName(state *ningen.State, userID discord.UserID) string {
+	dms, _ := state.Cabinet.PrivateChannels()
+	for _, dm := range dms {
+		for _, recipient := range dm.DMRecipients {
+			if recipient.ID == userID {
+				return recipient.DisplayOrUsername()
+			}
+		}
+	}
+
+	if presence, _ := state.PresenceStore.Presence(0, userID); presence != nil && presence.User.Username != "" {
+		return presence.User.DisplayOrUsername()
+	}
+
+	return userID.String()
+}
+
 // ChannelName returns the name of a channel, or a list of recipients if it's a
 // DM.
 func ChannelName(ch *discord.Channel, short bool) string {
@@ -348,16 +492,16 @@ func ChannelName(ch *discord.Channel, short bool) string {
 	case 0:
 		return ch.ID.Mention()
 	case 1:
-		return ch.DMRecipients[0].Username
+		return ch.DMRecipients[0].DisplayOrUsername()
 	default:
 		recipientNames := make([]string, len(ch.DMRecipients))
 		for i, recipient := range ch.DMRecipients {
-			recipientNames[i] = recipient.Username
+			recipientNames[i] = recipient.DisplayOrUsername()
 		}
 
 		if short {
 			const maxNames = 3
-			names := strings.Join(recipientNames[:maxNames], ", ")
+			names := strings.Join(recipientNames[:min(maxNames, len(recipientNames))], ", ")
 			if len(recipientNames) > maxNames {
 				names += ", ..."
 			}
@@ -1,12 +1,16 @@
 package bot
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/diamondburned/arikawa/v3/api"
 	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/ningen/v3"
 	"github.com/pkg/errors"
 	"github.com/twipi/twipi/proto/out/twicmdproto"
 	"github.com/twipi/twipi/twicmd"
@@ -26,12 +30,82 @@ func (s *Session) Execute(ctx context.Context, req *twicmdproto.ExecuteRequest)
 		return s.executeNick(ctx, req), nil
 	case "guild_nick":
 		return s.executeGuildNick(ctx, req), nil
+	case "guild_alias":
+		return s.executeGuildAlias(ctx, req), nil
+	case "aliases":
+		return s.executeAliases(ctx, req), nil
+	case "unnick":
+		return s.executeUnnick(ctx, req), nil
+	case "renick":
+		return s.executeRenick(ctx, req), nil
 	case "mute":
 		return s.executeMute(ctx, req), nil
 	case "unmute":
 		return s.executeUnmute(ctx, req), nil
 	case "notifications":
 		return s.executeNotifications(ctx, req), nil
+	case "mentions":
+		return s.executeMentions(ctx, req), nil
+	case "watch":
+		return s.executeWatch(ctx, req), nil
+	case "guild_watch":
+		return s.executeGuildWatch(ctx, req), nil
+	case "channel_watch":
+		return s.executeChannelWatch(ctx, req), nil
+	case "unwatch":
+		return s.executeUnwatch(ctx, req), nil
+	case "watches":
+		return s.executeWatches(ctx, req), nil
+	case "vip":
+		return s.executeVIP(ctx, req), nil
+	case "unvip":
+		return s.executeUnvip(ctx, req), nil
+	case "block":
+		return s.executeBlock(ctx, req), nil
+	case "block_role":
+		return s.executeBlockRole(ctx, req), nil
+	case "block_content":
+		return s.executeBlockContent(ctx, req), nil
+	case "unblock":
+		return s.executeUnblock(ctx, req), nil
+	case "blocks":
+		return s.executeBlocks(ctx, req), nil
+	case "budget":
+		return s.executeBudget(ctx, req), nil
+	case "timezone":
+		return s.executeTimezone(ctx, req), nil
+	case "digest":
+		return s.executeDigest(ctx, req), nil
+	case "deletes":
+		return s.executeDeletes(ctx, req), nil
+	case "react":
+		return s.executeReact(ctx, req), nil
+	case "edit":
+		return s.executeEdit(ctx, req), nil
+	case "unsend":
+		return s.executeUnsend(ctx, req), nil
+	case "reply":
+		return s.executeReply(ctx, req), nil
+	case "threads":
+		return s.executeThreads(ctx, req), nil
+	case "pick":
+		return s.executePick(ctx, req), nil
+	case "focus":
+		return s.executeFocus(ctx, req), nil
+	case "unfocus":
+		return s.executeUnfocus(ctx, req), nil
+	case "focused_text":
+		return s.executeFocusedText(ctx, req), nil
+	case "later":
+		return s.executeLater(ctx, req), nil
+	case "scheduled":
+		return s.executeScheduled(ctx, req), nil
+	case "cancel":
+		return s.executeCancel(ctx, req), nil
+	case "remind":
+		return s.executeRemind(ctx, req), nil
+	case "template":
+		return s.executeTemplate(ctx, req), nil
 	default:
 		return nil, errors.New("unknown command")
 	}
@@ -48,35 +122,44 @@ func (s *Session) internalErrorResponse(req *twicmdproto.ExecuteRequest, err err
 func (s *Session) executeMessage(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
 	args := twicmd.MapArguments(req.Command.Arguments)
 
-	r, err := searchChannel(ctx, s.State, s.store, "", args["channel"])
-	if err != nil {
-		return twicmd.StatusResponse(err.Error())
-	}
+	return s.searchRecipientThen(ctx, "", args["channel"], func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		content, err := s.expandTemplate(ctx, args["message"], r.name())
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
 
-	_, err = s.State.SendMessage(r.Channel.ID, args["message"])
-	if err != nil {
-		return s.internalErrorResponse(req, err)
-	}
+		ch, err := dmChannel(s.State, r)
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
 
-	return nil
+		_, err = s.sendMessage(ctx, ch.ID, api.SendMessageData{
+			Content: content,
+		})
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+		return nil
+	})
 }
 
 func (s *Session) executeNick(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
 	args := twicmd.MapArguments(req.Command.Arguments)
 
-	r, err := searchChannel(ctx, s.State, s.store, "", args["channel"])
-	if err != nil {
-		return twicmd.StatusResponse(err.Error())
-	}
+	return s.searchChannelThen(ctx, "", args["channel"], func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		if err := s.checkChannelNickname(ctx, args["nickname"], r.Channel.ID); err != nil {
+			return s.aliasErrorResponse(req, err)
+		}
 
-	if err := s.store.SetChannelNickname(ctx, r.Channel.ID, args["nickname"]); err != nil {
-		return s.internalErrorResponse(req, err)
-	}
+		if err := s.store.SetChannelNickname(ctx, r.Channel.ID, args["nickname"]); err != nil {
+			return s.internalErrorResponse(req, err)
+		}
 
-	response := fmt.Sprintf(
-		"Set %q to channel %q.",
-		args["nickname"], ChannelName(r.Channel, true))
-	return twicmd.TextResponse(response)
+		response := fmt.Sprintf(
+			"Set %q to channel %q.",
+			args["nickname"], ChannelName(r.Channel, true))
+		return twicmd.TextResponse(response)
+	})
 }
 
 func (s *Session) executeGuildNick(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
@@ -85,18 +168,39 @@ func (s *Session) executeGuildNick(ctx context.Context, req *twicmdproto.Execute
 		return twicmd.StatusResponse("you must specify a guild")
 	}
 
-	r, err := searchChannel(ctx, s.State, s.store, args["guild"], args["channel"])
+	return s.searchChannelThen(ctx, args["guild"], args["channel"], func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		if err := s.checkChannelNickname(ctx, args["nickname"], r.Channel.ID); err != nil {
+			return s.aliasErrorResponse(req, err)
+		}
+
+		if err := s.store.SetChannelNickname(ctx, r.Channel.ID, args["nickname"]); err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		response := fmt.Sprintf(
+			"Set %q to channel %q in guild %q.",
+			args["nickname"], ChannelName(r.Channel, true), r.Guild.Name)
+		return twicmd.TextResponse(response)
+	})
+}
+
+func (s *Session) executeGuildAlias(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	guild, err := searchGuild(ctx, s.State, s.store, args["guild"])
 	if err != nil {
 		return twicmd.StatusResponse(err.Error())
 	}
 
-	if err := s.store.SetChannelNickname(ctx, r.Channel.ID, args["nickname"]); err != nil {
+	if err := s.checkGuildAlias(ctx, args["alias"], guild.ID); err != nil {
+		return s.aliasErrorResponse(req, err)
+	}
+
+	if err := s.store.SetGuildAlias(ctx, guild.ID, args["alias"]); err != nil {
 		return s.internalErrorResponse(req, err)
 	}
 
-	response := fmt.Sprintf(
-		"Set %q to channel %q in guild %q.",
-		args["nickname"], ChannelName(r.Channel, true), r.Guild.Name)
+	response := fmt.Sprintf("Set %q to guild %q.", args["alias"], guild.Name)
 	return twicmd.TextResponse(response)
 }
 
@@ -131,41 +235,160 @@ func (s *Session) executeUnmute(ctx context.Context, req *twicmdproto.ExecuteReq
 	return twicmd.TextResponse(repsonse)
 }
 
-func (s *Session) executeNotifications(_ context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
-	dms, err := s.State.Cabinet.PrivateChannels()
-	if err != nil {
-		return s.internalErrorResponse(req, err)
-	}
+func (s *Session) executeNotifications(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
 
-	type unreadChannel struct {
-		discord.Channel
-		UnreadCount int
+	var includeDMs, includeGuilds bool
+	var guilds []discord.Guild
+
+	switch filter := strings.TrimSpace(args["filter"]); filter {
+	case "":
+		includeDMs = true
+		includeGuilds = true
+	case "dms", "dm":
+		includeDMs = true
+	default:
+		guild, err := searchGuild(ctx, s.State, s.store, filter)
+		if err != nil {
+			return twicmd.StatusResponse(err.Error())
+		}
+		includeGuilds = true
+		guilds = []discord.Guild{*guild}
 	}
 
-	var unreads []unreadChannel
+	var buf strings.Builder
+	var total int
+
+	if includeDMs {
+		unreads, err := s.unreadPrivateChannels()
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		if len(unreads) > 0 {
+			buf.WriteString("DMs:\n")
+			for _, unread := range unreads {
+				fmt.Fprintf(&buf, "%s (%d)\n", ChannelName(&unread.Channel, true), unread.UnreadCount)
+			}
+			total += len(unreads)
+		}
+	}
 
-	for _, dm := range dms {
-		if s.State.ChannelIsMuted(dm.ID, true) {
-			continue
+	if includeGuilds {
+		if guilds == nil {
+			var err error
+			guilds, err = s.State.Cabinet.Guilds()
+			if err != nil {
+				return s.internalErrorResponse(req, err)
+			}
 		}
 
-		count := s.State.ChannelCountUnreads(dm.ID)
-		if count > 0 {
-			unreads = append(unreads, unreadChannel{
-				Channel:     dm,
-				UnreadCount: count,
-			})
+		for _, guild := range guilds {
+			mentioned := s.mentionedGuildChannels(guild.ID)
+			if len(mentioned) == 0 {
+				continue
+			}
+
+			var mentions int
+			for _, ch := range mentioned {
+				mentions += ch.MentionCount
+			}
+
+			fmt.Fprintf(&buf, "%s (%d mentions):\n", s.guildName(ctx, &guild), mentions)
+			for _, ch := range mentioned {
+				fmt.Fprintf(&buf, "#%s (%d)\n", ChannelName(&ch.Channel, true), ch.MentionCount)
+			}
+			total += len(mentioned)
 		}
 	}
 
-	if len(unreads) == 0 {
+	if total == 0 {
 		return twicmd.StatusResponse("No unread messages.")
 	}
 
+	response := fmt.Sprintf("You have %d unread channels:\n%s", total, buf.String())
+	return twicmd.TextResponse(strings.TrimSuffix(response, "\n"))
+}
+
+func (s *Session) executeMentions(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	var guilds []discord.Guild
+	if search := strings.TrimSpace(args["guild"]); search != "" {
+		guild, err := searchGuild(ctx, s.State, s.store, search)
+		if err != nil {
+			return twicmd.StatusResponse(err.Error())
+		}
+		guilds = []discord.Guild{*guild}
+	} else {
+		var err error
+		guilds, err = s.State.Cabinet.Guilds()
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+	}
+
+	const maxMentions = 10
+
+	type mentionMessage struct {
+		discord.Message
+		Channel *discord.Channel
+		Guild   *discord.Guild
+	}
+
+	var mentions []mentionMessage
+
+	for i := range guilds {
+		guild := &guilds[i]
+
+		for _, ch := range s.mentionedGuildChannels(guild.ID) {
+			msgs, err := s.State.Messages(ch.ID, 50)
+			if err != nil {
+				s.logger.Warn(
+					"failed to get messages for mentions",
+					"channel_id", ch.ID,
+					"err", err,
+					*s.logAttrs.Load())
+				continue
+			}
+
+			for _, msg := range msgs {
+				if msg.ID <= ch.LastReadID {
+					break
+				}
+				if s.State.MessageMentions(&msg)&ningen.MessageMentions == 0 {
+					continue
+				}
+				mentions = append(mentions, mentionMessage{
+					Message: msg,
+					Channel: &ch.Channel,
+					Guild:   guild,
+				})
+			}
+		}
+	}
+
+	if len(mentions) == 0 {
+		return twicmd.StatusResponse("No unread mentions.")
+	}
+
+	slices.SortFunc(mentions, func(a, b mentionMessage) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if len(mentions) > maxMentions {
+		mentions = mentions[:maxMentions]
+	}
+
 	var buf strings.Builder
-	fmt.Fprintf(&buf, "You have %d unread channels:\n", len(unreads))
-	for _, unread := range unreads {
-		fmt.Fprintf(&buf, "%s (%d)\n", ChannelName(&unread.Channel, true), unread.UnreadCount)
+	for _, mention := range mentions {
+		content := renderText(s.logger, s.State, mention.Content, &mention.Message)
+		fmt.Fprintf(&buf,
+			"%s in #%s (%s):\n%s\n",
+			s.authorName(&mention.Message),
+			ChannelName(mention.Channel, true),
+			s.guildName(ctx, mention.Guild),
+			truncateText(strings.TrimSpace(content), 80))
 	}
-	return twicmd.TextResponse(buf.String())
+
+	return twicmd.TextResponse(strings.TrimSuffix(buf.String(), "\n"))
 }
@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// defaultFocusDuration is how long focus mode lasts if no duration is given.
+const defaultFocusDuration = 30 * time.Minute
+
+// focusedChannel returns the channel that is currently focused, or an invalid
+// ID if focus mode is off.
+func (s *Session) focusedChannel() discord.ChannelID {
+	s.focus.Lock()
+	defer s.focus.Unlock()
+
+	if !s.focus.channelID.IsValid() || time.Now().After(s.focus.until) {
+		return 0
+	}
+	return s.focus.channelID
+}
+
+// Focused returns whether a channel is focused on.
+func (s *Session) Focused() bool {
+	return s.focusedChannel().IsValid()
+}
+
+// deferUnfocused holds back the message if another channel is focused. The
+// message is persisted as pending, so that it is still queued once focus mode
+// ends if we restart in the meantime.
+func (s *Session) deferUnfocused(ctx context.Context, chID discord.ChannelID, msgID discord.MessageID) bool {
+	if !s.holdUnfocused(chID, msgID) {
+		return false
+	}
+
+	if err := s.store.AddPendingMessage(ctx, store.PendingMessage{
+		ChannelID: chID,
+		MessageID: msgID,
+	}); err != nil {
+		s.logger.Error(
+			"failed to persist deferred message",
+			"channel_id", chID,
+			"message_id", msgID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	return true
+}
+
+// holdUnfocused holds back the message until focus mode ends if another
+// channel is focused.
+func (s *Session) holdUnfocused(chID discord.ChannelID, msgID discord.MessageID) bool {
+	s.focus.Lock()
+	defer s.focus.Unlock()
+
+	if !s.focus.channelID.IsValid() || s.focus.channelID == chID {
+		return false
+	}
+
+	s.focus.deferred = append(s.focus.deferred, store.PendingMessage{
+		ChannelID: chID,
+		MessageID: msgID,
+	})
+	return true
+}
+
+// restoreFocus restores focus mode from before we last stopped. Messages that
+// were deferred are held back again once they're replayed.
+func (s *Session) restoreFocus(ctx context.Context) {
+	focus, err := s.store.Focus(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to restore focus",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	switch {
+	case !focus.ChannelID.IsValid():
+		return
+	case focus.Until.After(time.Now()):
+		s.setFocus(ctx, focus.ChannelID, focus.Until)
+	default:
+		// Focus mode timed out while we were stopped. The deferred messages
+		// are still pending, so they're replayed like any other.
+		if err := s.store.ClearFocus(ctx); err != nil {
+			s.logger.Error(
+				"failed to clear focus",
+				"err", err,
+				*s.logAttrs.Load())
+		}
+		s.sendSMS(ctx, "Focus mode timed out.")
+	}
+}
+
+// startFocus focuses on the channel until the given time, replacing any
+// previous focus.
+func (s *Session) startFocus(ctx context.Context, chID discord.ChannelID, until time.Time) error {
+	if err := s.store.SetFocus(ctx, store.Focus{
+		ChannelID: chID,
+		Until:     until,
+	}); err != nil {
+		return errors.Wrap(err, "failed to save focus")
+	}
+
+	s.setFocus(ctx, chID, until)
+	return nil
+}
+
+// setFocus focuses on the channel until the given time without saving it.
+func (s *Session) setFocus(ctx context.Context, chID discord.ChannelID, until time.Time) {
+	ctx = context.WithoutCancel(ctx)
+
+	s.focus.Lock()
+	defer s.focus.Unlock()
+
+	if s.focus.timer != nil {
+		s.focus.timer.Stop()
+	}
+
+	s.focus.channelID = chID
+	s.focus.until = until
+	s.focus.timer = time.AfterFunc(time.Until(until), func() {
+		if s.stopFocus(ctx, chID) {
+			s.sendSMS(ctx, "Focus mode timed out.")
+		}
+	})
+}
+
+// stopFocus ends focus mode and queues all messages that were deferred. If
+// chID is valid, then focus mode is only stopped if it is still focused on
+// that channel. It returns true if focus mode was stopped.
+func (s *Session) stopFocus(ctx context.Context, chID discord.ChannelID) bool {
+	s.focus.Lock()
+
+	if !s.focus.channelID.IsValid() || (chID.IsValid() && s.focus.channelID != chID) {
+		s.focus.Unlock()
+		return false
+	}
+
+	deferred := s.focus.deferred
+	if s.focus.timer != nil {
+		s.focus.timer.Stop()
+	}
+	s.focus.channelID = 0
+	s.focus.until = time.Time{}
+	s.focus.timer = nil
+	s.focus.deferred = nil
+
+	s.focus.Unlock()
+
+	if err := s.store.ClearFocus(ctx); err != nil {
+		s.logger.Error(
+			"failed to clear focus",
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	for _, msg := range deferred {
+		s.queueMessage(ctx, msg.ChannelID, msg.MessageID, 5*time.Second)
+	}
+
+	return true
+}
+
+// pauseFocus stops the focus timer when the session stops. Focus mode itself
+// stays saved, so that it's restored once we start again.
+func (s *Session) pauseFocus() {
+	s.focus.Lock()
+	defer s.focus.Unlock()
+
+	if s.focus.timer != nil {
+		s.focus.timer.Stop()
+		s.focus.timer = nil
+	}
+}
+
+func (s *Session) executeFocus(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	duration := defaultFocusDuration
+	if v := strings.TrimSpace(args["duration"]); v != "" {
+		d, err := str2duration.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return twicmd.StatusResponse("failed to parse duration")
+		}
+		duration = d
+	}
+
+	return s.searchChannelThen(ctx, "", args["channel"], func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		if err := s.startFocus(ctx, r.Channel.ID, time.Now().Add(duration)); err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		response := fmt.Sprintf(
+			"Focused on %s for %s. Any text that isn't a command is sent to it, "+
+				"until you unfocus. Messages from other channels are held until then.",
+			s.channelHeader(ctx, r.Channel, r.Guild), duration)
+		return twicmd.TextResponse(response)
+	})
+}
+
+func (s *Session) executeUnfocus(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	if !s.stopFocus(ctx, 0) {
+		return twicmd.StatusResponse("you are not focused on any channel")
+	}
+	return twicmd.StatusResponse("Unfocused. Held messages will be sent shortly.")
+}
+
+// executeFocusedText sends a text that isn't a command to the focused channel.
+// Such texts are parsed into this command by the focusparser package.
+func (s *Session) executeFocusedText(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	chID := s.focusedChannel()
+	if !chID.IsValid() {
+		return twicmd.StatusResponse(
+			"You are not focused on any channel. Use focus <channel> to send texts to one, " +
+				"or message <channel> <message> to send a single message.")
+	}
+
+	text := strings.TrimSpace(args["text"])
+	if text == "" {
+		return nil
+	}
+
+	var recipient string
+	if ch, err := s.State.Cabinet.Channel(chID); err == nil {
+		recipient = ChannelName(ch, true)
+	}
+
+	text, err := s.expandTemplate(ctx, text, recipient)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if _, err := s.sendMessage(ctx, chID, api.SendMessageData{Content: text}); err != nil {
+		return s.internalErrorResponse(req, errors.Wrap(err, "failed to send to focused channel"))
+	}
+
+	return nil
+}
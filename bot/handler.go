@@ -5,14 +5,17 @@ import (
 	"log/slog"
 	"os"
 	"runtime"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/arikawa/v3/gateway"
 	"github.com/diamondburned/ningen/v3"
 	"github.com/twipi/twidiscord/store"
 	"github.com/twipi/twipi/twisms"
+	"golang.org/x/time/rate"
 )
 
 var hostname string
@@ -26,6 +29,9 @@ func init() {
 	}
 }
 
+// closeTimeout is how long a closing session may take to send what's left.
+const closeTimeout = 10 * time.Second
+
 // Session is a Discord SMS gateway session.
 type Session struct {
 	*ningen.State
@@ -43,6 +49,39 @@ type Session struct {
 		sessions []gateway.UserSession
 	}
 	throttlers *messageThrottlers
+	coalescer  *smsCoalescer
+	watchRules atomic.Pointer[[]watchRule]
+	vipUsers   atomic.Pointer[map[discord.UserID]struct{}]
+	blockRules atomic.Pointer[[]blockRule]
+
+	budget struct {
+		sync.Mutex
+		limiter *rate.Limiter
+		hourly  int
+		retry   *time.Timer
+		noticed string
+		// flushing is held while held texts are being sent.
+		flushing sync.Mutex
+	}
+
+	digest struct {
+		sync.Mutex
+		entries []store.DigestEntry
+		last    time.Time
+	}
+
+	pick struct {
+		sync.Mutex
+		pending *pendingPick
+	}
+
+	focus struct {
+		sync.Mutex
+		channelID discord.ChannelID
+		until     time.Time
+		timer     *time.Timer
+		deferred  []store.PendingMessage
+	}
 }
 
 type messageFragment struct {
@@ -87,16 +126,57 @@ func NewSession(store store.AccountStore, sms twisms.MessageSender, logger *slog
 // Start starts the handler.
 func (s *Session) Start(ctx context.Context) error {
 	s.State = s.State.WithContext(ctx)
-	s.bindDiscord()
+	s.bindDiscord(ctx)
+	s.reloadWatchRules(ctx)
+	s.reloadVIPUsers(ctx)
+	s.reloadBlockRules(ctx)
+
+	s.coalescer = newSMSCoalescer(
+		ctx, 3*time.Second, 6,
+		func(ctx context.Context, sms outgoingSMS) { s.sendOutgoingSMS(ctx, sms) },
+	)
 
 	s.throttlers = newMessageThrottlers(
 		15,
 		s.logger.With("component", "message_throttler"),
 		func(chID discord.ChannelID, ids []discord.MessageID) {
-			s.sendMessageIDs(ctx, chID, ids)
+			// Messages that are being sent stay pending until their SMS
+			// is delivered. The rest are done with.
+			sending := s.sendMessageIDs(ctx, chID, ids)
+			s.removePending(ctx, filterSlice(ids, func(id discord.MessageID) bool {
+				return !slices.Contains(sending, id)
+			}))
 		},
 	)
-	defer s.throttlers.wg.Wait()
 
-	return s.State.Connect(ctx)
+	s.restoreDigest(ctx)
+	s.restoreFocus(ctx)
+	s.retryHeldSMS(ctx, time.Now())
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.runDigests(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		s.runPruning(ctx)
+	}()
+
+	err := s.State.Connect(ctx)
+
+	// Send whatever is still waiting to be merged, now that no more messages
+	// are coming in. The session's context is done by now, so give it a bit
+	// of time of its own.
+	s.throttlers.wg.Wait()
+	s.pauseFocus()
+
+	closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), closeTimeout)
+	defer cancel()
+	s.coalescer.Close(closeCtx)
+
+	return err
 }
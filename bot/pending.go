@@ -0,0 +1,235 @@
+package bot
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+)
+
+const (
+	// forwardedRetention is how long forwarded message IDs are remembered
+	// for deduplication.
+	forwardedRetention = 30 * 24 * time.Hour
+	// pruneInterval is how often old records are pruned from the store.
+	pruneInterval = 24 * time.Hour
+)
+
+// queueMessage queues the message for sending and persists it so that it can
+// be replayed if we restart before it is sent.
+func (s *Session) queueMessage(ctx context.Context, chID discord.ChannelID, msgID discord.MessageID, delay time.Duration) {
+	if err := s.store.AddPendingMessage(ctx, store.PendingMessage{
+		ChannelID: chID,
+		MessageID: msgID,
+	}); err != nil {
+		s.logger.Error(
+			"failed to persist pending message",
+			"channel_id", chID,
+			"message_id", msgID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	throttler := s.throttlers.forChannel(chID)
+	if delay == 0 {
+		throttler.SendNow(msgID)
+	} else {
+		throttler.AddMessage(msgID, delay)
+	}
+}
+
+// removePending removes the given messages from the persisted queue once
+// they've been delivered or dropped.
+func (s *Session) removePending(ctx context.Context, ids []discord.MessageID) {
+	for _, id := range ids {
+		if err := s.store.RemovePendingMessage(ctx, id); err != nil {
+			s.logger.Error(
+				"failed to remove pending message",
+				"message_id", id,
+				"err", err,
+				*s.logAttrs.Load())
+		}
+	}
+}
+
+// replayPending queues all messages that were still pending when we last
+// stopped.
+func (s *Session) replayPending(ctx context.Context) {
+	pending, err := s.store.PendingMessages(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load pending messages",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	// Messages in held texts are still pending, but they're sent along with
+	// their text once the budget allows it.
+	held := make(map[discord.MessageID]bool)
+	heldSMS, err := s.store.HeldSMS(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load held SMS",
+			"err", err,
+			*s.logAttrs.Load())
+	}
+	for _, h := range heldSMS {
+		for _, msg := range h.Forwarded {
+			held[msg.MessageID] = true
+		}
+	}
+
+	pending = filterSlice(pending, func(msg store.PendingMessage) bool {
+		return !held[msg.MessageID]
+	})
+
+	for _, msg := range pending {
+		// Messages deferred by focus mode are held back again until it ends.
+		if s.holdUnfocused(msg.ChannelID, msg.MessageID) {
+			continue
+		}
+		s.throttlers.forChannel(msg.ChannelID).AddMessage(msg.MessageID, 5*time.Second)
+	}
+
+	if len(pending) > 0 {
+		s.logger.Info(
+			"replayed pending messages",
+			"count", len(pending),
+			*s.logAttrs.Load())
+	}
+}
+
+type forwardState uint8
+
+const (
+	// notForwarded means the message was never forwarded.
+	notForwarded forwardState = iota
+	// forwardedUnchanged means the message was already forwarded and its
+	// content hasn't meaningfully changed since.
+	forwardedUnchanged
+	// forwardedEdited means the message was already forwarded but has since
+	// been edited.
+	forwardedEdited
+)
+
+// forwardState returns whether the message was already forwarded and whether
+// it was edited since.
+func (s *Session) forwardState(ctx context.Context, msg *discord.Message) forwardState {
+	forwarded, err := s.store.ForwardedMessage(ctx, msg.ID)
+	if err != nil {
+		if !errors.Is(err, store.ErrNotFound) {
+			s.logger.Error(
+				"failed to check if message was forwarded",
+				"message_id", msg.ID,
+				"err", err,
+				*s.logAttrs.Load())
+		}
+		return notForwarded
+	}
+
+	// Messages forwarded before we recorded hashes are never re-sent.
+	if forwarded.ContentHash == "" || forwarded.ContentHash == contentHash(msg.Content) {
+		return forwardedUnchanged
+	}
+
+	return forwardedEdited
+}
+
+// contentHash hashes the message content, ignoring differences in whitespace
+// and letter case.
+func contentHash(content string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(content), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:16])
+}
+
+// forwardedMessages returns the records of the given messages being
+// forwarded with their current content.
+func (s *Session) forwardedMessages(msgs []discord.Message) []store.ForwardedMessage {
+	forwarded := make([]store.ForwardedMessage, len(msgs))
+	for i := range msgs {
+		msg := &msgs[i]
+		forwarded[i] = store.ForwardedMessage{
+			ChannelID:   msg.ChannelID,
+			MessageID:   msg.ID,
+			ContentHash: contentHash(msg.Content),
+			Author:      s.authorName(msg),
+		}
+	}
+	return forwarded
+}
+
+// forwardedIDs returns the IDs of the forwarded messages.
+func forwardedIDs(forwarded []store.ForwardedMessage) []discord.MessageID {
+	ids := make([]discord.MessageID, len(forwarded))
+	for i, msg := range forwarded {
+		ids[i] = msg.MessageID
+	}
+	return ids
+}
+
+// markForwarded remembers that the given messages were forwarded now.
+func (s *Session) markForwarded(ctx context.Context, forwarded []store.ForwardedMessage) {
+	now := time.Now()
+	for _, msg := range forwarded {
+		msg.ForwardedAt = now
+		if err := s.store.SetMessageForwarded(ctx, msg); err != nil {
+			s.logger.Error(
+				"failed to mark message as forwarded",
+				"message_id", msg.MessageID,
+				"err", err,
+				*s.logAttrs.Load())
+		}
+	}
+}
+
+// runPruning prunes old records from the store now and then once a day until
+// ctx is canceled.
+func (s *Session) runPruning(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		s.pruneHistory(ctx, time.Now().In(s.location(ctx)))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pruneHistory forgets about forwarded and sent messages, SMS usage and
+// channel usage that are too old to matter anymore.
+func (s *Session) pruneHistory(ctx context.Context, now time.Time) {
+	// SMS usage is kept for the last month as well, so that it can be
+	// looked back at.
+	lastMonth := startOfDay(now).AddDate(0, -1, 1-now.Day())
+
+	prunes := []struct {
+		what  string
+		prune func(context.Context, time.Time) error
+		until time.Time
+	}{
+		{"forwarded messages", s.store.PruneForwardedMessages, now.Add(-forwardedRetention)},
+		{"sent messages", s.store.PruneSentMessages, now.Add(-sentRetention)},
+		{"SMS usage", s.store.PruneSMSUsage, lastMonth},
+		{"channel usage", s.store.PruneChannelUsage, now.Add(-channelUsageRetention)},
+	}
+
+	for _, p := range prunes {
+		if err := p.prune(ctx, p.until); err != nil {
+			s.logger.Error(
+				"failed to prune "+p.what,
+				"err", err,
+				*s.logAttrs.Load())
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package bot
+
+import "testing"
+
+func TestContentHash(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		equal bool
+	}{
+		{"same", "hello world", "hello world", true},
+		{"case", "Hello World", "hello world", true},
+		{"whitespace", "hello   world\n", " hello world", true},
+		{"newlines", "hello\nworld", "hello world", true},
+		{"different words", "hello world", "hello there", false},
+		{"punctuation", "hello world", "hello world!", false},
+		{"empty", "", "   ", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := contentHash(test.a), contentHash(test.b)
+			if (a == b) != test.equal {
+				t.Errorf("contentHash(%q) = %s, contentHash(%q) = %s, want equal = %v",
+					test.a, a, test.b, b, test.equal)
+			}
+			if len(a) != 32 {
+				t.Errorf("contentHash(%q) has length %d, want 32", test.a, len(a))
+			}
+		})
+	}
+}
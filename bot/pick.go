@@ -0,0 +1,168 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// pickTimeout is how long an ambiguous command waits for the user to pick a
+// channel.
+const pickTimeout = 5 * time.Minute
+
+type channelAction func(context.Context, *channelSearchResult) *twicmdproto.ExecuteResponse
+
+type userAction func(context.Context, discord.UserID) *twicmdproto.ExecuteResponse
+
+type pendingPick struct {
+	candidates []*channelSearchResult
+	action     channelAction
+	expires    time.Time
+}
+
+// searchChannelThen searches for an existing channel and runs the action on
+// it. If the search is ambiguous, then the candidates are listed and the action
+// is held until the user picks one of them.
+func (s *Session) searchChannelThen(ctx context.Context, guildSearch, channelSearch string, action channelAction) *twicmdproto.ExecuteResponse {
+	return s.searchRecipientThen(ctx, guildSearch, channelSearch, requireChannel(action))
+}
+
+// searchRecipientThen is like searchChannelThen, except that the action may
+// also be given a user that has no DM yet. Only actions that send a message to
+// them should create the DM.
+func (s *Session) searchRecipientThen(ctx context.Context, guildSearch, channelSearch string, action channelAction) *twicmdproto.ExecuteResponse {
+	r, err := searchChannel(ctx, s.State, s.store, guildSearch, channelSearch)
+	if err != nil {
+		var ambiguous *ambiguousChannelError
+		if !errors.As(err, &ambiguous) {
+			return twicmd.StatusResponse(err.Error())
+		}
+		return s.holdPick(ctx, ambiguous, "channels", action)
+	}
+
+	return action(ctx, r)
+}
+
+// searchUserThen searches for a user and runs the action on them. If the
+// search is ambiguous, then the candidates are listed and the action is held
+// until the user picks one of them.
+func (s *Session) searchUserThen(ctx context.Context, userSearch string, action userAction) *twicmdproto.ExecuteResponse {
+	userID, err := searchUser(ctx, s.State, s.store, userSearch)
+	if err != nil {
+		var ambiguous *ambiguousChannelError
+		if !errors.As(err, &ambiguous) {
+			return twicmd.StatusResponse(err.Error())
+		}
+		return s.holdPick(ctx, ambiguous, "users", func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+			return action(ctx, r.User.ID)
+		})
+	}
+
+	return action(ctx, userID)
+}
+
+// holdPick holds the action until the user picks one of the candidates, which
+// are either channels or users, and returns the list of candidates.
+func (s *Session) holdPick(ctx context.Context, ambiguous *ambiguousChannelError, what string, action channelAction) *twicmdproto.ExecuteResponse {
+	s.pick.Lock()
+	s.pick.pending = &pendingPick{
+		candidates: ambiguous.Candidates,
+		action:     action,
+		expires:    time.Now().Add(pickTimeout),
+	}
+	s.pick.Unlock()
+
+	return twicmd.TextResponse(s.candidatesText(ctx, ambiguous, what))
+}
+
+// requireChannel wraps the action to reply with an error if it's given a user
+// that has no DM yet.
+func requireChannel(action channelAction) channelAction {
+	return func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		if r.Channel == nil {
+			return twicmd.StatusResponse(noDMError(r.User).Error())
+		}
+		return action(ctx, r)
+	}
+}
+
+// noDMError returns the error for when a channel is needed but only a user
+// that has no DM yet was found.
+func noDMError(user *discord.User) error {
+	return fmt.Errorf("you have no DM with %s yet, send them a message first", user.DisplayOrUsername())
+}
+
+func (s *Session) candidatesText(ctx context.Context, ambiguous *ambiguousChannelError, what string) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Multiple %s match %q, reply with pick <n>:\n", what, ambiguous.Search)
+
+	for i, c := range ambiguous.Candidates {
+		fmt.Fprintf(&buf, "%d. ", i+1)
+		if c.Channel == nil {
+			fmt.Fprintf(&buf, "%s (@%s)", c.User.DisplayOrUsername(), c.User.Username)
+			if what == "channels" {
+				buf.WriteString(", new DM")
+			}
+		} else {
+			buf.WriteString(s.channelHeader(ctx, c.Channel, c.Guild))
+			if c.Channel.LastMessageID.IsValid() {
+				fmt.Fprintf(&buf, ", active %s", sinceString(c.Channel.LastMessageID.Time()))
+			}
+		}
+		buf.WriteByte('\n')
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// sinceString formats the time since t for displaying to the user.
+func sinceString(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+	}
+}
+
+func (s *Session) executePick(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	s.pick.Lock()
+	pending := s.pick.pending
+	if pending != nil && time.Now().After(pending.expires) {
+		pending = nil
+	}
+	s.pick.Unlock()
+
+	if pending == nil {
+		return twicmd.StatusResponse("there is nothing to pick")
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(args["n"]))
+	if err != nil || n < 1 || n > len(pending.candidates) {
+		return twicmd.StatusResponse(fmt.Sprintf("pick a number from 1 to %d", len(pending.candidates)))
+	}
+
+	r := pending.candidates[n-1]
+
+	s.pick.Lock()
+	if s.pick.pending == pending {
+		s.pick.pending = nil
+	}
+	s.pick.Unlock()
+
+	return pending.action(ctx, r)
+}
@@ -0,0 +1,142 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// emojiShortcodes maps commonly used emoji shortcodes to their Unicode emoji.
+var emojiShortcodes = map[string]string{
+	"+1":                     "👍",
+	"thumbsup":               "👍",
+	"-1":                     "👎",
+	"thumbsdown":             "👎",
+	"heart":                  "❤️",
+	"joy":                    "😂",
+	"rofl":                   "🤣",
+	"smile":                  "😄",
+	"slight_smile":           "🙂",
+	"laughing":               "😆",
+	"sob":                    "😭",
+	"cry":                    "😢",
+	"thinking":               "🤔",
+	"eyes":                   "👀",
+	"ok_hand":                "👌",
+	"wave":                   "👋",
+	"clap":                   "👏",
+	"pray":                   "🙏",
+	"tada":                   "🎉",
+	"fire":                   "🔥",
+	"rocket":                 "🚀",
+	"100":                    "💯",
+	"white_check_mark":       "✅",
+	"heavy_check_mark":       "✔️",
+	"x":                      "❌",
+	"warning":                "⚠️",
+	"skull":                  "💀",
+	"star":                   "⭐",
+	"sparkles":               "✨",
+	"upside_down":            "🙃",
+	"neutral_face":           "😐",
+	"angry":                  "😠",
+	"open_mouth":             "😮",
+	"point_up":               "☝️",
+	"raised_hands":           "🙌",
+	"muscle":                 "💪",
+	"saluting_face":          "🫡",
+	"heart_eyes":             "😍",
+	"sweat_smile":            "😅",
+	"face_with_rolling_eyes": "🙄",
+}
+
+// resolveEmoji resolves the emoji given to the react command. It accepts
+// Unicode emoji, :shortcode: names and the names of custom emoji, preferring
+// ones from the given guild.
+func (s *Session) resolveEmoji(guildID discord.GuildID, emoji string) (discord.APIEmoji, error) {
+	name, isShortcode := strings.CutPrefix(emoji, ":")
+	if isShortcode {
+		name = strings.TrimSuffix(name, ":")
+	} else if !isEmojiName(emoji) {
+		// Assume that anything that isn't a name is a Unicode emoji.
+		return discord.APIEmoji(emoji), nil
+	}
+
+	// Names work with or without colons, since they're a pain to type on
+	// some phones.
+	if unicode, ok := emojiShortcodes[strings.ToLower(name)]; ok {
+		return discord.APIEmoji(unicode), nil
+	}
+
+	if custom := s.searchCustomEmoji(guildID, name); custom != nil {
+		return custom.APIString(), nil
+	}
+
+	return "", fmt.Errorf("unknown emoji %q", emoji)
+}
+
+// searchCustomEmoji searches for a custom emoji by its name, first in the
+// given guild and then in all other guilds.
+func (s *Session) searchCustomEmoji(guildID discord.GuildID, name string) *discord.Emoji {
+	if guildID.IsValid() {
+		emojis, _ := s.State.Cabinet.Emojis(guildID)
+		if emoji := findEmoji(emojis, name); emoji != nil {
+			return emoji
+		}
+	}
+
+	guilds, _ := s.State.Cabinet.Guilds()
+	for _, guild := range guilds {
+		if guild.ID == guildID {
+			continue
+		}
+		emojis, _ := s.State.Cabinet.Emojis(guild.ID)
+		if emoji := findEmoji(emojis, name); emoji != nil {
+			return emoji
+		}
+	}
+
+	return nil
+}
+
+func findEmoji(emojis []discord.Emoji, name string) *discord.Emoji {
+	for i, emoji := range emojis {
+		if strings.EqualFold(emoji.Name, name) {
+			return &emojis[i]
+		}
+	}
+	return nil
+}
+
+// isEmojiName returns true if the string looks like an emoji name rather than
+// a Unicode emoji.
+func isEmojiName(s string) bool {
+	for _, r := range s {
+		if !(r == '_' || r == '-' || r == '+' ||
+			('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func (s *Session) executeReact(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	return s.messageRefThen(ctx, req, args["ref"], func(ctx context.Context, msg *discord.Message) *twicmdproto.ExecuteResponse {
+		emoji, err := s.resolveEmoji(msg.GuildID, strings.TrimSpace(args["emoji"]))
+		if err != nil {
+			return twicmd.StatusResponse(err.Error())
+		}
+
+		if err := s.State.React(msg.ChannelID, msg.ID, emoji); err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/httputil"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+type messageAction func(context.Context, *discord.Message) *twicmdproto.ExecuteResponse
+
+// messageRefThen resolves a reference to a Discord message and runs the action
+// on it. A reference is either "^n" for the nth most recently forwarded
+// message ("^" alone being the latest), or the name of a channel for the last
+// message in that channel. If the channel name is ambiguous, then the action
+// is held until the user picks the channel.
+func (s *Session) messageRefThen(ctx context.Context, req *twicmdproto.ExecuteRequest, ref string, action messageAction) *twicmdproto.ExecuteResponse {
+	if strings.HasPrefix(ref, "^") {
+		n, rest := cutMessageRef(ref)
+		if rest != "" {
+			return twicmd.StatusResponse(fmt.Sprintf("invalid message reference %q", ref))
+		}
+
+		msg, err := s.forwardedMessage(ctx, n)
+		if err != nil {
+			return s.refErrorResponse(req, err)
+		}
+		return action(ctx, msg)
+	}
+
+	return s.searchChannelThen(ctx, "", ref, func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		msg, err := s.lastMessage(r.Channel.ID)
+		if err != nil {
+			return s.refErrorResponse(req, err)
+		}
+		return action(ctx, msg)
+	})
+}
+
+// messageRefError is returned if a message reference doesn't point at any
+// message, as opposed to looking up the message failing.
+type messageRefError struct {
+	msg string
+}
+
+func (err *messageRefError) Error() string {
+	return err.msg
+}
+
+// refErrorResponse returns the response for an error from resolving a message
+// reference.
+func (s *Session) refErrorResponse(req *twicmdproto.ExecuteRequest, err error) *twicmdproto.ExecuteResponse {
+	var refErr *messageRefError
+	if errors.As(err, &refErr) {
+		return twicmd.StatusResponse(err.Error())
+	}
+	return s.internalErrorResponse(req, err)
+}
+
+// cutMessageRef cuts an optional "^n" reference from the start of the
+// arguments, returning n and the rest of the arguments. n is 1 if there is no
+// reference. Arguments that merely start with "^", like "^_^", are not a
+// reference and are returned as they are.
+func cutMessageRef(args string) (int, string) {
+	args = strings.TrimSpace(args)
+
+	ref, rest, _ := strings.Cut(args, " ")
+	switch {
+	case ref == "^":
+		return 1, strings.TrimSpace(rest)
+	case strings.HasPrefix(ref, "^"):
+		n, err := strconv.Atoi(ref[1:])
+		if err == nil && n >= 1 && ref[1] != '+' {
+			return n, strings.TrimSpace(rest)
+		}
+	}
+
+	return 1, args
+}
+
+// forwardedMessage returns the nth most recently forwarded message.
+func (s *Session) forwardedMessage(ctx context.Context, n int) (*discord.Message, error) {
+	forwarded, err := s.store.RecentForwardedMessage(ctx, n)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, &messageRefError{fmt.Sprintf("no forwarded message ^%d", n)}
+		}
+		return nil, err
+	}
+
+	msg, err := s.State.Message(forwarded.ChannelID, forwarded.MessageID)
+	if err != nil {
+		var httpErr *httputil.HTTPError
+		if errors.As(err, &httpErr) && httpErr.Status == http.StatusNotFound {
+			return nil, &messageRefError{fmt.Sprintf("message ^%d no longer exists", n)}
+		}
+		return nil, fmt.Errorf("failed to get message ^%d: %w", n, err)
+	}
+	return msg, nil
+}
+
+// lastMessage returns the last message in the channel that wasn't sent by the
+// current user.
+func (s *Session) lastMessage(chID discord.ChannelID) (*discord.Message, error) {
+	me, err := s.State.Cabinet.Me()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get self user: %w", err)
+	}
+
+	msgs, err := s.State.Messages(chID, 50)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	// Messages are ordered from latest.
+	for i := range msgs {
+		if msgs[i].Author.ID != me.ID {
+			return &msgs[i], nil
+		}
+	}
+
+	return nil, &messageRefError{"no messages in channel"}
+}
@@ -0,0 +1,34 @@
+package bot
+
+import "testing"
+
+func TestCutMessageRef(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		n    int
+		rest string
+	}{
+		{"empty", "", 1, ""},
+		{"caret", "^", 1, ""},
+		{"caret with text", "^ hello", 1, "hello"},
+		{"numbered", "^2", 2, ""},
+		{"numbered with text", "^2  hi there", 2, "hi there"},
+		{"no ref", "hello", 1, "hello"},
+		{"emoticon", "^_^", 1, "^_^"},
+		{"emoticon with text", "^_^ nice", 1, "^_^ nice"},
+		{"zero", "^0 x", 1, "^0 x"},
+		{"negative", "^-1 x", 1, "^-1 x"},
+		{"plus", "^+2 x", 1, "^+2 x"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n, rest := cutMessageRef(test.args)
+			if n != test.n || rest != test.rest {
+				t.Errorf("cutMessageRef(%q) = (%d, %q), want (%d, %q)",
+					test.args, n, rest, test.n, test.rest)
+			}
+		})
+	}
+}
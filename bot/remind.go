@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// SendReminders sends all reminders that are due.
+func (s *Session) SendReminders(ctx context.Context) {
+	reminders, err := s.store.Reminders(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load reminders",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		if reminder.RemindAt.After(now) {
+			// Reminders are ordered by time, so nothing else is due.
+			break
+		}
+
+		if err := s.store.RemoveReminder(ctx, reminder.ID); err != nil {
+			if !errors.Is(err, store.ErrNotFound) {
+				s.logger.Error(
+					"failed to remove reminder",
+					"id", reminder.ID,
+					"err", err,
+					*s.logAttrs.Load())
+			}
+			continue
+		}
+
+		s.sendSMS(ctx, reminderText(reminder))
+	}
+}
+
+// reminderText formats the reminder for sending over SMS.
+func reminderText(reminder store.Reminder) string {
+	var buf strings.Builder
+	buf.WriteString("Reminder")
+	if reminder.Note != "" {
+		buf.WriteString(": ")
+		buf.WriteString(reminder.Note)
+	}
+	if reminder.Quote != "" {
+		buf.WriteByte('\n')
+		buf.WriteString(reminder.Quote)
+	}
+	if reminder.Link != "" {
+		buf.WriteByte('\n')
+		buf.WriteString(reminder.Link)
+	}
+	return buf.String()
+}
+
+func (s *Session) executeRemind(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	duration, err := str2duration.ParseDuration(args["duration"])
+	if err != nil || duration <= 0 {
+		return twicmd.StatusResponse("failed to parse duration")
+	}
+
+	reminder := store.Reminder{
+		RemindAt: time.Now().Add(duration),
+	}
+
+	text := strings.TrimSpace(args["text"])
+	if n, note := cutMessageRef(text); text == "" || note != text {
+		// Point at a forwarded message, the latest one if no text is given.
+		msg, err := s.forwardedMessage(ctx, n)
+		if err != nil {
+			return s.refErrorResponse(req, err)
+		}
+
+		content := renderText(s.logger, s.State, msg.Content, msg)
+		reminder.Note = note
+		reminder.Quote = s.authorName(msg) + ": " + truncateText(strings.TrimSpace(content), 160)
+		reminder.Link = msg.URL()
+	} else {
+		reminder.Note = text
+	}
+
+	if _, err := s.store.AddReminder(ctx, reminder); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	return twicmd.TextResponse(fmt.Sprintf("Will remind you in %s.", duration))
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"context"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/arikawa/v3/utils/json/option"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// noPingFlag is the flag given to the reply command to not ping the author of
+// the replied message.
+const noPingFlag = "-q"
+
+func (s *Session) executeReply(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	n, text := cutMessageRef(args["text"])
+
+	text, noPing := strings.CutPrefix(text, noPingFlag+" ")
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return twicmd.StatusResponse("you must specify the reply text")
+	}
+
+	replied, err := s.forwardedMessage(ctx, n)
+	if err != nil {
+		return s.refErrorResponse(req, err)
+	}
+
+	text, err = s.expandTemplate(ctx, text, s.authorName(replied))
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	data := api.SendMessageData{
+		Content: text,
+		Reference: &discord.MessageReference{
+			MessageID: replied.ID,
+			ChannelID: replied.ChannelID,
+		},
+	}
+
+	if noPing {
+		data.AllowedMentions = &api.AllowedMentions{
+			Parse: []api.AllowedMentionType{
+				api.AllowUserMention,
+				api.AllowRoleMention,
+				api.AllowEveryoneMention,
+			},
+			RepliedUser: option.False,
+		}
+	}
+
+	if _, err := s.sendMessage(ctx, replied.ChannelID, data); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,233 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+const (
+	// maxScheduledAttempts is how many times sending a scheduled message is
+	// attempted before giving up on it.
+	maxScheduledAttempts = 5
+	// scheduledRetryDelay is how long to wait before retrying to send a
+	// scheduled message for the first time. It doubles with every attempt.
+	scheduledRetryDelay = time.Minute
+)
+
+// SendScheduled sends all scheduled messages that are due. Messages that fail
+// to send are retried a few times before the user is told.
+func (s *Session) SendScheduled(ctx context.Context) {
+	msgs, err := s.store.ScheduledMessages(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load scheduled messages",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	now := time.Now()
+	for _, msg := range msgs {
+		if msg.SendAt.After(now) {
+			// Messages are ordered by time, so nothing else is due.
+			break
+		}
+
+		if err := s.sendScheduled(ctx, msg); err != nil {
+			s.logger.Error(
+				"failed to send scheduled message",
+				"id", msg.ID,
+				"channel_id", msg.ChannelID,
+				"attempts", msg.Attempts+1,
+				"err", err,
+				*s.logAttrs.Load())
+
+			if msg.Attempts+1 < maxScheduledAttempts {
+				s.retryScheduled(ctx, msg, now)
+				continue
+			}
+
+			s.sendSMS(ctx, fmt.Sprintf(
+				"Failed to send scheduled message to %s, giving up: %q",
+				s.scheduledChannelName(ctx, msg), truncateText(msg.Content, 80)))
+		}
+
+		if err := s.store.RemoveScheduledMessage(ctx, msg.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			s.logger.Error(
+				"failed to remove scheduled message",
+				"id", msg.ID,
+				"err", err,
+				*s.logAttrs.Load())
+		}
+	}
+}
+
+// retryScheduled reschedules the message after it failed to send, backing off
+// with every attempt.
+func (s *Session) retryScheduled(ctx context.Context, msg store.ScheduledMessage, now time.Time) {
+	retryAt := now.Add(scheduledRetryDelay << msg.Attempts)
+	if err := s.store.RetryScheduledMessage(ctx, msg.ID, retryAt); err != nil && !errors.Is(err, store.ErrNotFound) {
+		s.logger.Error(
+			"failed to reschedule scheduled message",
+			"id", msg.ID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+}
+
+// sendScheduled sends the scheduled message, creating the DM that it's sent to
+// if there was none when it was scheduled.
+func (s *Session) sendScheduled(ctx context.Context, msg store.ScheduledMessage) error {
+	chID := msg.ChannelID
+	if !chID.IsValid() {
+		ch, err := createDM(s.State, msg.UserID)
+		if err != nil {
+			return err
+		}
+		chID = ch.ID
+	}
+
+	_, err := s.sendMessage(ctx, chID, api.SendMessageData{
+		Content: msg.Content,
+	})
+	return err
+}
+
+// parseSendTime parses either a duration from now or a 24-hour HH:MM time of
+// day in the time zone of now. Times of day that already passed today refer
+// to tomorrow.
+func parseSendTime(now time.Time, when string) (time.Time, error) {
+	if d, err := str2duration.ParseDuration(when); err == nil {
+		if d <= 0 {
+			return time.Time{}, errors.New("the duration must be positive")
+		}
+		return now.Add(d), nil
+	}
+
+	t, err := time.Parse("15:04", when)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, use a duration like 2h or a 24-hour HH:MM time", when)
+	}
+
+	y, m, d := now.Date()
+	at := time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !at.After(now) {
+		at = time.Date(y, m, d+1, t.Hour(), t.Minute(), 0, 0, now.Location())
+	}
+	return at, nil
+}
+
+// scheduledChannelName returns the name of the channel that the scheduled
+// message is sent to.
+func (s *Session) scheduledChannelName(ctx context.Context, msg store.ScheduledMessage) string {
+	if !msg.ChannelID.IsValid() {
+		return UserName(s.State, msg.UserID)
+	}
+
+	ch, err := s.State.Cabinet.Channel(msg.ChannelID)
+	if err != nil {
+		return msg.ChannelID.Mention()
+	}
+	guild, _ := s.State.Cabinet.Guild(ch.GuildID)
+	return s.channelHeader(ctx, ch, guild)
+}
+
+func (s *Session) executeLater(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	loc := s.location(ctx)
+
+	sendAt, err := parseSendTime(time.Now().In(loc), strings.TrimSpace(args["when"]))
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	content := strings.TrimSpace(args["message"])
+	if content == "" {
+		return twicmd.StatusResponse("you must specify a message")
+	}
+
+	return s.searchRecipientThen(ctx, "", args["channel"], func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		content, err := s.expandTemplate(ctx, content, r.name())
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		// Don't create a DM until the message is sent, since it may still
+		// be canceled.
+		msg := store.ScheduledMessage{
+			Content: content,
+			SendAt:  sendAt,
+		}
+		to := r.name()
+		if r.Channel != nil {
+			msg.ChannelID = r.Channel.ID
+			to = s.channelHeader(ctx, r.Channel, r.Guild)
+		} else {
+			msg.UserID = r.User.ID
+		}
+
+		id, err := s.store.AddScheduledMessage(ctx, msg)
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		response := fmt.Sprintf(
+			"Scheduled message %d to %s at %s.",
+			id, to, sendAt.Format("Jan 2 15:04"))
+		return twicmd.TextResponse(response)
+	})
+}
+
+func (s *Session) executeScheduled(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	msgs, err := s.store.ScheduledMessages(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if len(msgs) == 0 {
+		return twicmd.StatusResponse("No scheduled messages.")
+	}
+
+	loc := s.location(ctx)
+
+	var buf strings.Builder
+	buf.WriteString("Scheduled messages:\n")
+	for _, msg := range msgs {
+		fmt.Fprintf(&buf,
+			"%d: %s to %s: %q\n",
+			msg.ID,
+			msg.SendAt.In(loc).Format("Jan 2 15:04"),
+			s.scheduledChannelName(ctx, msg),
+			truncateText(msg.Content, 40))
+	}
+	return twicmd.TextResponse(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+func (s *Session) executeCancel(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	id, err := strconv.ParseInt(args["id"], 10, 64)
+	if err != nil {
+		return twicmd.StatusResponse("invalid scheduled message ID")
+	}
+
+	if err := s.store.RemoveScheduledMessage(ctx, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return twicmd.StatusResponse("no such scheduled message")
+		}
+		return s.internalErrorResponse(req, err)
+	}
+
+	return twicmd.TextResponse(fmt.Sprintf("Canceled scheduled message %d.", id))
+}
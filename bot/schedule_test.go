@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseSendTime(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		when string
+		want time.Time
+		err  bool
+	}{
+		{
+			name: "duration",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "2h30m",
+			want: time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "days",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "1d",
+			want: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "later today",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "18:45",
+			want: time.Date(2024, 5, 1, 18, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "tomorrow",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "09:00",
+			want: time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "now is tomorrow",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "10:00",
+			want: time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "user timezone",
+			now:  time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC).In(tokyo), // 08:00 JST
+			when: "09:00",
+			want: time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "across daylight saving time",
+			now:  time.Date(2024, 3, 9, 22, 0, 0, 0, newYork),
+			when: "09:00",
+			want: time.Date(2024, 3, 10, 9, 0, 0, 0, newYork),
+		},
+		{
+			name: "zero duration",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "0s",
+			err:  true,
+		},
+		{
+			name: "invalid time",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "25:00",
+			err:  true,
+		},
+		{
+			name: "garbage",
+			now:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+			when: "soon",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseSendTime(test.now, test.when)
+			if test.err {
+				if err == nil {
+					t.Errorf("parseSendTime() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSendTime() failed: %v", err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("parseSendTime() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
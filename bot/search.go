@@ -1,21 +1,91 @@
 package bot
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
 
 	"github.com/diamondburned/arikawa/v3/discord"
 	"github.com/diamondburned/ningen/v3"
 	"github.com/twipi/twidiscord/store"
 	"github.com/pkg/errors"
 	"github.com/sahilm/fuzzy"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
+// channelSearchResult is a channel found by searchChannel. If a user with no
+// DM channel yet was found instead, then only User is set, and the DM must be
+// created by the caller if it wants to send a message.
 type channelSearchResult struct {
 	Channel *discord.Channel
 	Guild   *discord.Guild
+	User    *discord.User
 }
 
+// name returns the name of the channel or user that was found.
+func (r *channelSearchResult) name() string {
+	if r.Channel == nil {
+		return r.User.DisplayOrUsername()
+	}
+	return ChannelName(r.Channel, true)
+}
+
+const (
+	// ambiguityMargin is the score difference within which fuzzy matches are
+	// considered equally good.
+	ambiguityMargin = 10
+	// maxCandidates is the maximum number of candidates given for an
+	// ambiguous search.
+	maxCandidates = 5
+	// activityLead is the channel ranking bonus by which a channel must lead
+	// the others that match about as well for it to be picked outright.
+	activityLead = 5
+)
+
+// ambiguousChannelError is returned by searchChannel if multiple channels
+// match the search about equally well.
+type ambiguousChannelError struct {
+	Search     string
+	Candidates []*channelSearchResult
+}
+
+func (e *ambiguousChannelError) Error() string {
+	names := make([]string, len(e.Candidates))
+	for i, c := range e.Candidates {
+		names[i] = c.name()
+	}
+	return fmt.Sprintf("%q matches %s, be more specific", e.Search, strings.Join(names, ", "))
+}
+
+func ambiguousChannels(state *ningen.State, search string, channels []discord.Channel) error {
+	candidates := make([]*channelSearchResult, len(channels))
+	for i := range channels {
+		guild, _ := state.Offline().Guild(channels[i].GuildID)
+		candidates[i] = &channelSearchResult{
+			Channel: &channels[i],
+			Guild:   guild,
+		}
+	}
+	return &ambiguousChannelError{Search: search, Candidates: candidates}
+}
+
+func ambiguousUsers(search string, users []knownUser) error {
+	candidates := make([]*channelSearchResult, len(users))
+	for i := range users {
+		candidates[i] = &channelSearchResult{User: &users[i].User}
+	}
+	return &ambiguousChannelError{Search: search, Candidates: candidates}
+}
+
+// searchChannel searches for a channel by its nickname or name. DMs are also
+// searched by the names of their recipient. If no channel matches, then users
+// that the current user has no DM with are searched, but no DM is created.
 func searchChannel(ctx context.Context, state *ningen.State, account store.AccountStore, guildSearch, channelSearch string) (*channelSearchResult, error) {
 	// Search for any channel nicknames first.
 	if id, err := account.ChannelFromNickname(ctx, channelSearch); err == nil {
@@ -44,25 +114,20 @@ func searchChannel(ctx context.Context, state *ningen.State, account store.Accou
 
 	if guildSearch != "" {
 		// Permit searching guild channels.
-		guilds, err := state.Offline().Guilds()
+		guild, err = searchGuild(ctx, state, account, guildSearch)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get list of guilds: %w", err)
-		}
-
-		guild = matchGuild(guilds, guildSearch)
-		if guild == nil {
-			return nil, errors.New("no such guild")
+			return nil, err
 		}
 
 		channels, err = state.Offline().Channels(guild.ID, []discord.ChannelType{
 			discord.GuildText,
 			discord.GuildVoice,
-			discord.GuildPublicThread,
-			discord.GuildPrivateThread,
+			discord.GuildAnnouncement,
 		})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get list of channels: %w", err)
 		}
+		channels = append(channels, activeThreads(state, guild.ID)...)
 	} else {
 		channels, err = state.Offline().PrivateChannels()
 		if err != nil {
@@ -70,19 +135,289 @@ func searchChannel(ctx context.Context, state *ningen.State, account store.Accou
 		}
 	}
 
-	channel := matchChannel(channels, channelSearch)
-	if channel == nil {
+	ranking := loadChannelRanking(ctx, account)
+	guilds, _ := state.Offline().Guilds()
+
+	matched := matchChannels(channels, channelSearch, ranking, func(id discord.UserID) []string {
+		member, _ := knownMember(state, guilds, id)
+		return member.Nicks
+	})
+	switch {
+	case len(matched) == 0 && guildSearch == "":
+		return searchNewChannel(state, guilds, channelSearch, ranking)
+	case len(matched) == 0:
 		return nil, errors.New("no such channel")
+	case len(matched) > 1:
+		return nil, ambiguousChannels(state, channelSearch, matched)
 	}
 
 	return &channelSearchResult{
-		Channel: channel,
+		Channel: &matched[0],
 		Guild:   guild,
 	}, nil
 }
 
+// searchNewChannel searches for a channel that isn't an existing DM. Friends
+// are searched first, then active threads and forum posts, then members of all
+// guilds.
+func searchNewChannel(state *ningen.State, guilds []discord.Guild, search string, ranking channelRanking) (*channelSearchResult, error) {
+	if users := matchUsers(friends(state, guilds), search); len(users) > 0 {
+		if len(users) > 1 {
+			return nil, ambiguousUsers(search, users)
+		}
+		return &channelSearchResult{User: &users[0].User}, nil
+	}
+
+	// Permit searching active threads and forum posts in any guild by their
+	// name, since they're usually uniquely named.
+	if channels := matchChannels(activeThreads(state, 0), search, ranking, nil); len(channels) > 0 {
+		if len(channels) > 1 {
+			return nil, ambiguousChannels(state, search, channels)
+		}
+		guild, err := state.Offline().Guild(channels[0].GuildID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get guild: %w", err)
+		}
+		return &channelSearchResult{
+			Channel: &channels[0],
+			Guild:   guild,
+		}, nil
+	}
+
+	if users := matchUsers(guildMembers(state), search); len(users) > 0 {
+		if len(users) > 1 {
+			return nil, ambiguousUsers(search, users)
+		}
+		return &channelSearchResult{User: &users[0].User}, nil
+	}
+
+	return nil, errors.New("no such channel")
+}
+
+// dmChannel returns the channel that was found, creating a DM with the user
+// that was found if there's no channel. Only commands that send a message
+// should create DMs.
+func dmChannel(state *ningen.State, r *channelSearchResult) (*discord.Channel, error) {
+	if r.Channel != nil {
+		return r.Channel, nil
+	}
+	return createDM(state, r.User.ID)
+}
+
+func createDM(state *ningen.State, userID discord.UserID) (*discord.Channel, error) {
+	channel, err := state.CreatePrivateChannel(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DM: %w", err)
+	}
+	return channel, nil
+}
+
+// knownUser is a user along with the nicknames they go by in guilds.
+type knownUser struct {
+	discord.User
+	Nicks []string
+}
+
+// names returns all names that the user may be searched by.
+func (u knownUser) names() []string {
+	return append([]string{u.DisplayOrUsername(), u.Username}, u.Nicks...)
+}
+
+// knownMember returns the user along with the nicknames they go by in the
+// given guilds. False is returned if they aren't a known member of any.
+func knownMember(state *ningen.State, guilds []discord.Guild, id discord.UserID) (knownUser, bool) {
+	var user knownUser
+	var found bool
+
+	for _, guild := range guilds {
+		member, err := state.Offline().Member(guild.ID, id)
+		if err != nil {
+			continue
+		}
+		if !found {
+			user.User = member.User
+			found = true
+		}
+		if member.Nick != "" && !slices.Contains(user.Nicks, member.Nick) {
+			user.Nicks = append(user.Nicks, member.Nick)
+		}
+	}
+
+	return user, found
+}
+
+// friends returns the users that the current user is friends with and whose
+// user information is known, along with their nicknames in the given guilds.
+func friends(state *ningen.State, guilds []discord.Guild) []knownUser {
+	var ids []discord.UserID
+	state.RelationshipState.Each(func(id discord.UserID, t discord.RelationshipType) bool {
+		if t == discord.FriendRelationship {
+			ids = append(ids, id)
+		}
+		return false
+	})
+
+	users := make([]knownUser, 0, len(ids))
+	for _, id := range ids {
+		if member, ok := knownMember(state, guilds, id); ok {
+			users = append(users, member)
+			continue
+		}
+		if presence, _ := state.PresenceStore.Presence(0, id); presence != nil && presence.User.Username != "" {
+			users = append(users, knownUser{User: presence.User})
+		}
+	}
+
+	return users
+}
+
+// guildMembers returns the users of all guild members that are known, without
+// duplicates, bots or the current user. This goes through every member of
+// every guild, so it should only be used as a last resort.
+func guildMembers(state *ningen.State) []knownUser {
+	guilds, _ := state.Offline().Guilds()
+
+	seen := make(map[discord.UserID]int)
+	if me, _ := state.Offline().Me(); me != nil {
+		seen[me.ID] = -1
+	}
+
+	var users []knownUser
+
+	for _, guild := range guilds {
+		members, _ := state.Offline().Members(guild.ID)
+		for _, member := range members {
+			if member.User.Bot {
+				continue
+			}
+			ix, ok := seen[member.User.ID]
+			if !ok {
+				ix = len(users)
+				seen[member.User.ID] = ix
+				users = append(users, knownUser{User: member.User})
+			}
+			if ix != -1 && member.Nick != "" && !slices.Contains(users[ix].Nicks, member.Nick) {
+				users[ix].Nicks = append(users[ix].Nicks, member.Nick)
+			}
+		}
+	}
+
+	return users
+}
+
+// searchUser searches for a user by a user ID, mention or name. Users in DMs,
+// including group DMs, and friends are searched first, then members of all
+// guilds. Unlike searchChannel, no DM is ever created.
+func searchUser(ctx context.Context, state *ningen.State, account store.AccountStore, userSearch string) (discord.UserID, error) {
+	trimmed := strings.TrimSuffix(strings.TrimPrefix(userSearch, "<@"), ">")
+	if sf, err := discord.ParseSnowflake(strings.TrimPrefix(trimmed, "!")); err == nil && sf.IsValid() {
+		return discord.UserID(sf), nil
+	}
+
+	// Permit using the nickname of a DM channel.
+	if id, err := account.ChannelFromNickname(ctx, userSearch); err == nil {
+		channel, err := state.Offline().Channel(id)
+		if err == nil && len(channel.DMRecipients) == 1 {
+			return channel.DMRecipients[0].ID, nil
+		}
+	}
+
+	for _, users := range []func(*ningen.State) []knownUser{recentUsers, guildMembers} {
+		matched := matchUsers(users(state), userSearch)
+		switch {
+		case len(matched) > 1:
+			return 0, ambiguousUsers(userSearch, matched)
+		case len(matched) == 1:
+			return matched[0].ID, nil
+		}
+	}
+
+	return 0, errors.New("no such user")
+}
+
+// recentUsers returns the recipients of all DMs and group DMs along with all
+// friends whose user information is known, without duplicates or the current
+// user.
+func recentUsers(state *ningen.State) []knownUser {
+	seen := make(map[discord.UserID]bool)
+	if me, _ := state.Offline().Me(); me != nil {
+		seen[me.ID] = true
+	}
+
+	var users []knownUser
+
+	channels, _ := state.Offline().PrivateChannels()
+	for _, channel := range channels {
+		for _, recipient := range channel.DMRecipients {
+			if !seen[recipient.ID] {
+				seen[recipient.ID] = true
+				users = append(users, knownUser{User: recipient})
+			}
+		}
+	}
+
+	for _, friend := range friends(state, nil) {
+		if !seen[friend.ID] {
+			seen[friend.ID] = true
+			users = append(users, friend)
+		}
+	}
+
+	return users
+}
+
+func searchGuild(ctx context.Context, state *ningen.State, account store.AccountStore, guildSearch string) (*discord.Guild, error) {
+	// Search for any guild aliases first.
+	if id, err := account.GuildFromAlias(ctx, guildSearch); err == nil {
+		guild, err := state.Offline().Guild(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get aliased guild: %w", err)
+		}
+		return guild, nil
+	}
+
+	guilds, err := state.Offline().Guilds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of guilds: %w", err)
+	}
+
+	guild := matchGuild(guilds, guildSearch)
+	if guild == nil {
+		return nil, errors.New("no such guild")
+	}
+
+	return guild, nil
+}
+
+func searchRole(state *ningen.State, guildID discord.GuildID, roleSearch string) (*discord.Role, error) {
+	roles, err := state.Offline().Roles(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of roles: %w", err)
+	}
+
+	matches := findFuzzy(strings.TrimPrefix(roleSearch, "@"), fuzzyRoles(roles))
+	bestMatch, ok := bestFuzzyMatch(matches)
+	if !ok {
+		return nil, errors.New("no such role")
+	}
+
+	return &roles[bestMatch.Index], nil
+}
+
+type fuzzyRoles []discord.Role
+
+var _ fuzzy.Source = fuzzyRoles{}
+
+func (r fuzzyRoles) Len() int {
+	return len(r)
+}
+
+func (r fuzzyRoles) String(i int) string {
+	return r[i].Name
+}
+
 func matchGuild(guilds []discord.Guild, search string) *discord.Guild {
-	matches := fuzzy.FindFromNoSort(search, fuzzyGuilds(guilds))
+	matches := findFuzzy(search, fuzzyGuilds(guilds))
 	bestMatch, ok := bestFuzzyMatch(matches)
 	if !ok {
 		return nil
@@ -103,7 +438,7 @@ func (g fuzzyGuilds) String(i int) string {
 }
 
 func matchChannel(channels []discord.Channel, search string) *discord.Channel {
-	matches := fuzzy.FindFromNoSort(search, fuzzyChannels(channels))
+	matches := findFuzzy(search, fuzzyChannels(channels))
 	bestMatch, ok := bestFuzzyMatch(matches)
 	if !ok {
 		return nil
@@ -111,6 +446,49 @@ func matchChannel(channels []discord.Channel, search string) *discord.Channel {
 	return &channels[bestMatch.Index]
 }
 
+// matchUsers returns the users that best match the search by any of their
+// names. More than one user is returned if the search is ambiguous.
+func matchUsers(users []knownUser, search string) []knownUser {
+	matches := findFuzzyNames(search, len(users), func(i int) []string {
+		return users[i].names()
+	})
+	matches = closeFuzzyMatches(matches, search)
+
+	matched := make([]knownUser, len(matches))
+	for i, match := range matches {
+		matched[i] = users[match.Index]
+	}
+	return matched
+}
+
+// matchChannels returns the channels that best match the search, boosted by
+// the given ranking. DMs are also matched by the usernames and guild nicknames
+// of their recipient. More than one channel is returned if the search is
+// ambiguous.
+func matchChannels(channels []discord.Channel, search string, ranking channelRanking, nicks func(discord.UserID) []string) []discord.Channel {
+	matches := findFuzzyNames(search, len(channels), func(i int) []string {
+		names := []string{ChannelName(&channels[i], false)}
+		if len(channels[i].DMRecipients) == 1 {
+			recipient := channels[i].DMRecipients[0]
+			names = append(names, recipient.Username)
+			if nicks != nil {
+				names = append(names, nicks(recipient.ID)...)
+			}
+		}
+		return names
+	})
+	matches = closeFuzzyMatches(matches, search)
+	matches = rankByActivity(matches, func(i int) int {
+		return ranking.bonus(&channels[i])
+	})
+
+	matched := make([]discord.Channel, len(matches))
+	for i, match := range matches {
+		matched[i] = channels[match.Index]
+	}
+	return matched
+}
+
 type fuzzyChannels []discord.Channel
 
 var _ fuzzy.Source = fuzzyChannels{}
@@ -137,3 +515,183 @@ func bestFuzzyMatch(matches []fuzzy.Match) (fuzzy.Match, bool) {
 
 	return matches[iMax], true
 }
+
+// closeFuzzyMatches returns the matches that scored about as well as the best
+// match, best first. A match that is exactly the search wins outright.
+func closeFuzzyMatches(matches []fuzzy.Match, search string) []fuzzy.Match {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	search = normalizeName(search)
+
+	var exact []fuzzy.Match
+	for _, match := range matches {
+		if strings.EqualFold(match.Str, search) {
+			exact = append(exact, match)
+		}
+	}
+	if len(exact) == 1 {
+		return exact
+	}
+
+	sorted := slices.Clone(matches)
+	slices.SortStableFunc(sorted, func(a, b fuzzy.Match) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	candidates := sorted[:1]
+	for _, match := range sorted[1:] {
+		if match.Score < sorted[0].Score-ambiguityMargin || len(candidates) == maxCandidates {
+			break
+		}
+		candidates = append(candidates, match)
+	}
+	return candidates
+}
+
+// rankByActivity orders the matches, which scored about as well, by their
+// score plus the bonus of their item, best first. The match whose bonus leads
+// all others by at least activityLead is returned alone, so that a frequent
+// contact wins over dormant channels with a similar name.
+func rankByActivity(matches []fuzzy.Match, bonus func(index int) int) []fuzzy.Match {
+	if len(matches) < 2 {
+		return matches
+	}
+
+	bonuses := make(map[int]int, len(matches))
+	for _, match := range matches {
+		bonuses[match.Index] = bonus(match.Index)
+	}
+
+	ranked := slices.Clone(matches)
+	slices.SortStableFunc(ranked, func(a, b fuzzy.Match) int {
+		return cmp.Compare(b.Score+bonuses[b.Index], a.Score+bonuses[a.Index])
+	})
+
+	byBonus := slices.Clone(matches)
+	slices.SortStableFunc(byBonus, func(a, b fuzzy.Match) int {
+		return cmp.Compare(bonuses[b.Index], bonuses[a.Index])
+	})
+	if bonuses[byBonus[0].Index]-bonuses[byBonus[1].Index] >= activityLead {
+		return byBonus[:1]
+	}
+
+	return ranked
+}
+
+// findFuzzy fuzzy-matches the search against the source, ignoring case and
+// accents.
+func findFuzzy(search string, source fuzzy.Source) []fuzzy.Match {
+	return fuzzy.FindFromNoSort(normalizeName(search), normalizedSource{source})
+}
+
+// findFuzzyNames fuzzy-matches the search against all names of n items. Only
+// the best match of each item is returned, with its Index being the item's.
+func findFuzzyNames(search string, n int, names func(i int) []string) []fuzzy.Match {
+	var source fuzzyNames
+	for i := 0; i < n; i++ {
+		for _, name := range names(i) {
+			if name != "" {
+				source.names = append(source.names, name)
+				source.items = append(source.items, i)
+			}
+		}
+	}
+
+	var matches []fuzzy.Match
+	best := make(map[int]int) // item -> index into matches
+
+	for _, match := range findFuzzy(search, source) {
+		match.Index = source.items[match.Index]
+		ix, ok := best[match.Index]
+		if !ok {
+			best[match.Index] = len(matches)
+			matches = append(matches, match)
+			continue
+		}
+		if match.Score > matches[ix].Score {
+			matches[ix] = match
+		}
+	}
+
+	return matches
+}
+
+type fuzzyNames struct {
+	names []string
+	items []int
+}
+
+var _ fuzzy.Source = fuzzyNames{}
+
+func (n fuzzyNames) Len() int {
+	return len(n.names)
+}
+
+func (n fuzzyNames) String(i int) string {
+	return n.names[i]
+}
+
+type normalizedSource struct {
+	fuzzy.Source
+}
+
+func (s normalizedSource) String(i int) string {
+	return normalizeName(s.Source.String(i))
+}
+
+// normalizeName folds the name into a lowercase form without accents, so that
+// "Zoë" matches "zoe".
+func normalizeName(name string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, name)
+	if err != nil {
+		folded = name
+	}
+	return strings.ToLower(folded)
+}
+
+// channelUsageRetention is how long a channel that the user stopped sending
+// messages to keeps its ranking.
+const channelUsageRetention = 90 * 24 * time.Hour
+
+// channelRanking ranks channels by how active they are and how often the user
+// sends messages to them.
+type channelRanking map[discord.ChannelID]store.ChannelUsage
+
+func loadChannelRanking(ctx context.Context, account store.AccountStore) channelRanking {
+	usage, err := account.ChannelUsage(ctx)
+	if err != nil {
+		return nil
+	}
+	return channelRanking(usage)
+}
+
+// bonus returns how active the channel is, from 0 to 9. It only decides
+// between channels that match about as well, so a frequent channel never hides
+// a clearly better match.
+func (r channelRanking) bonus(ch *discord.Channel) int {
+	var lastActive time.Time
+	if ch.LastMessageID.IsValid() {
+		lastActive = ch.LastMessageID.Time()
+	}
+
+	usage := r[ch.ID]
+	if usage.LastSentAt.After(lastActive) {
+		lastActive = usage.LastSentAt
+	}
+
+	var bonus int
+	switch since := time.Since(lastActive); {
+	case since < 24*time.Hour:
+		bonus += 5
+	case since < 7*24*time.Hour:
+		bonus += 3
+	case since < 30*24*time.Hour:
+		bonus += 1
+	}
+
+	bonus += min(usage.SendCount, 20) / 5
+	return bonus
+}
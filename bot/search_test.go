@@ -0,0 +1,253 @@
+package bot
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/sahilm/fuzzy"
+)
+
+func TestFindFuzzyNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		search string
+		items  [][]string
+		want   []int // matched items, in any order
+	}{
+		{
+			name:   "no match",
+			search: "xyz",
+			items:  [][]string{{"general"}, {"random"}},
+			want:   nil,
+		},
+		{
+			name:   "single name",
+			search: "gen",
+			items:  [][]string{{"general"}, {"random"}},
+			want:   []int{0},
+		},
+		{
+			name:   "any name",
+			search: "bob",
+			items:  [][]string{{"alice", "ally"}, {"Robert", "bobby"}},
+			want:   []int{1},
+		},
+		{
+			name:   "one match per item",
+			search: "al",
+			items:  [][]string{{"alice", "ally", "al"}, {"bob"}},
+			want:   []int{0},
+		},
+		{
+			name:   "accents and case",
+			search: "zoe",
+			items:  [][]string{{"Zoë"}, {"zed"}},
+			want:   []int{0},
+		},
+		{
+			name:   "empty names",
+			search: "a",
+			items:  [][]string{{""}, {"", "a"}},
+			want:   []int{1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches := findFuzzyNames(test.search, len(test.items), func(i int) []string {
+				return test.items[i]
+			})
+
+			var got []int
+			for _, match := range matches {
+				got = append(got, match.Index)
+			}
+			slices.Sort(got)
+
+			if !slices.Equal(got, test.want) {
+				t.Errorf("findFuzzyNames() matched items %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFindFuzzyNamesBestScore(t *testing.T) {
+	names := []string{"general-chat", "gen"}
+	matches := findFuzzyNames("gen", 1, func(int) []string { return names })
+	if len(matches) != 1 {
+		t.Fatalf("findFuzzyNames() returned %d matches, want 1", len(matches))
+	}
+
+	for _, name := range names {
+		other := findFuzzy("gen", fuzzyNames{names: []string{name}})
+		if len(other) == 1 && other[0].Score > matches[0].Score {
+			t.Errorf("findFuzzyNames() kept score %d, but %q scores %d", matches[0].Score, name, other[0].Score)
+		}
+	}
+}
+
+func TestCloseFuzzyMatches(t *testing.T) {
+	match := func(str string, score int) fuzzy.Match {
+		return fuzzy.Match{Str: str, Score: score}
+	}
+
+	tests := []struct {
+		name    string
+		search  string
+		matches []fuzzy.Match
+		want    []string
+	}{
+		{
+			name:    "none",
+			search:  "a",
+			matches: nil,
+			want:    nil,
+		},
+		{
+			name:    "clear winner",
+			search:  "gen",
+			matches: []fuzzy.Match{match("general", 50), match("gaming", 20)},
+			want:    []string{"general"},
+		},
+		{
+			name:    "within margin",
+			search:  "gen",
+			matches: []fuzzy.Match{match("general", 40), match("genshin", 45), match("gaming", 10)},
+			want:    []string{"genshin", "general"},
+		},
+		{
+			name:    "at margin",
+			search:  "gen",
+			matches: []fuzzy.Match{match("general", 40), match("genshin", 40+ambiguityMargin)},
+			want:    []string{"genshin", "general"},
+		},
+		{
+			name:    "past margin",
+			search:  "gen",
+			matches: []fuzzy.Match{match("general", 39), match("genshin", 40+ambiguityMargin)},
+			want:    []string{"genshin"},
+		},
+		{
+			name:    "exact wins",
+			search:  "Gen",
+			matches: []fuzzy.Match{match("general", 60), match("gen", 40)},
+			want:    []string{"gen"},
+		},
+		{
+			name:    "exact accents",
+			search:  "Zoë",
+			matches: []fuzzy.Match{match("zoey", 60), match("zoe", 40)},
+			want:    []string{"zoe"},
+		},
+		{
+			name:    "multiple exact",
+			search:  "gen",
+			matches: []fuzzy.Match{match("gen", 40), match("gen", 45), match("general", 42)},
+			want:    []string{"gen", "general", "gen"},
+		},
+		{
+			name:   "max candidates",
+			search: "a",
+			matches: []fuzzy.Match{
+				match("a1", 10), match("a2", 9), match("a3", 8), match("a4", 7),
+				match("a5", 6), match("a6", 5), match("a7", 4),
+			},
+			want: []string{"a1", "a2", "a3", "a4", "a5"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, match := range closeFuzzyMatches(test.matches, test.search) {
+				got = append(got, match.Str)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("closeFuzzyMatches() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestChannelRankingBonus(t *testing.T) {
+	ch := discord.Channel{
+		ID:            1,
+		LastMessageID: discord.MessageID(discord.NewSnowflake(time.Now())),
+	}
+	r := channelRanking{
+		ch.ID: {SendCount: 1000, LastSentAt: time.Now()},
+	}
+
+	// The most active channel must be able to win over dormant ones.
+	if bonus := r.bonus(&ch); bonus < activityLead {
+		t.Errorf("bonus() = %d, below activityLead %d", bonus, activityLead)
+	}
+	if bonus := r.bonus(&discord.Channel{ID: 2}); bonus != 0 {
+		t.Errorf("bonus() of an unused channel = %d, want 0", bonus)
+	}
+}
+
+func TestRankByActivity(t *testing.T) {
+	match := func(index, score int) fuzzy.Match {
+		return fuzzy.Match{Index: index, Score: score}
+	}
+
+	tests := []struct {
+		name    string
+		matches []fuzzy.Match
+		bonuses []int // by index
+		want    []int // indices
+	}{
+		{
+			name:    "single",
+			matches: []fuzzy.Match{match(0, 40)},
+			bonuses: []int{0},
+			want:    []int{0},
+		},
+		{
+			name:    "tie without activity",
+			matches: []fuzzy.Match{match(0, 40), match(1, 40)},
+			bonuses: []int{0, 0},
+			want:    []int{0, 1},
+		},
+		{
+			name:    "frequent contact wins tie",
+			matches: []fuzzy.Match{match(0, 40), match(1, 40), match(2, 38)},
+			bonuses: []int{0, 9, 1},
+			want:    []int{1},
+		},
+		{
+			name:    "frequent contact wins slightly better match",
+			matches: []fuzzy.Match{match(0, 45), match(1, 40)},
+			bonuses: []int{0, activityLead},
+			want:    []int{1},
+		},
+		{
+			name:    "small lead only orders",
+			matches: []fuzzy.Match{match(0, 40), match(1, 40)},
+			bonuses: []int{3, 5},
+			want:    []int{1, 0},
+		},
+		{
+			name:    "two active channels",
+			matches: []fuzzy.Match{match(0, 40), match(1, 40), match(2, 40)},
+			bonuses: []int{9, 0, 8},
+			want:    []int{0, 2, 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []int
+			ranked := rankByActivity(test.matches, func(i int) int { return test.bonuses[i] })
+			for _, match := range ranked {
+				got = append(got, match.Index)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("rankByActivity() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diamondburned/arikawa/v3/api"
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// sentRetention is how long sent messages can be edited or unsent for.
+const sentRetention = 30 * 24 * time.Hour
+
+// sendMessage sends a message to Discord on behalf of the user and remembers
+// it so that it can be edited or unsent later.
+func (s *Session) sendMessage(ctx context.Context, chID discord.ChannelID, data api.SendMessageData) (*discord.Message, error) {
+	msg, err := s.State.SendMessageComplex(chID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.store.AddSentMessage(ctx, store.SentMessage{
+		ChannelID: chID,
+		MessageID: msg.ID,
+		SentAt:    time.Now(),
+	}); err != nil {
+		s.logger.Error(
+			"failed to remember sent message",
+			"channel_id", chID,
+			"message_id", msg.ID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	// Remember that the user talks in this channel for ranking searches.
+	if err := s.store.AddChannelSend(ctx, chID, time.Now()); err != nil {
+		s.logger.Error(
+			"failed to record channel usage",
+			"channel_id", chID,
+			"err", err,
+			*s.logAttrs.Load())
+	}
+
+	return msg, nil
+}
+
+// recentSentMessage returns the nth most recent message sent on behalf of the
+// user.
+func (s *Session) recentSentMessage(ctx context.Context, n int) (store.SentMessage, error) {
+	sent, err := s.store.RecentSentMessage(ctx, n)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			if n == 1 {
+				return sent, &messageRefError{"you haven't sent any messages"}
+			}
+			return sent, &messageRefError{fmt.Sprintf("no sent message ^%d", n)}
+		}
+		return sent, err
+	}
+	return sent, nil
+}
+
+func (s *Session) executeEdit(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	n, content := cutMessageRef(args["text"])
+	if content == "" {
+		return twicmd.StatusResponse("you must specify the new text")
+	}
+
+	sent, err := s.recentSentMessage(ctx, n)
+	if err != nil {
+		return s.refErrorResponse(req, err)
+	}
+
+	if _, err := s.State.EditMessage(sent.ChannelID, sent.MessageID, content); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	return nil
+}
+
+func (s *Session) executeUnsend(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	n, rest := cutMessageRef(args["ref"])
+	if rest != "" {
+		return twicmd.StatusResponse("usage: unsend [^n]")
+	}
+
+	sent, err := s.recentSentMessage(ctx, n)
+	if err != nil {
+		return s.refErrorResponse(req, err)
+	}
+
+	if err := s.State.DeleteMessage(sent.ChannelID, sent.MessageID, ""); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if err := s.store.RemoveSentMessage(ctx, sent.MessageID); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	return nil
+}
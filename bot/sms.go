@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"strings"
+	"unicode/utf16"
+)
+
+// gsm7Basic is the GSM 03.38 basic character set. Each character takes one
+// septet.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension is the GSM 03.38 extension table. Each character takes two
+// septets, since it has to be escaped.
+const gsm7Extension = "\f^{}\\[~]|€"
+
+// smsSegments returns the number of SMS segments that the given text will be
+// split into when sent.
+func smsSegments(text string) int {
+	if text == "" {
+		return 1
+	}
+
+	septets := 0
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(gsm7Basic, r):
+			septets++
+		case strings.ContainsRune(gsm7Extension, r):
+			septets += 2
+		default:
+			return segmentsFor(len(utf16.Encode([]rune(text))), 70, 67)
+		}
+	}
+
+	return segmentsFor(septets, 160, 153)
+}
+
+// segmentsFor returns the number of segments needed for n units, given the
+// number of units that fit in a single segment and in each segment of a
+// concatenated message.
+func segmentsFor(n, single, multi int) int {
+	if n <= single {
+		return 1
+	}
+	return (n + multi - 1) / multi
+}
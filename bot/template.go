@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// templatePrefix is the prefix that marks a message as a quick-reply template,
+// e.g. "/omw".
+const templatePrefix = "/"
+
+// expandTemplate replaces the message with the template that it names, if the
+// message consists of only "/name". Messages that don't name an existing
+// template, like "/shrug" or "/etc/hosts", are sent as they are.
+func (s *Session) expandTemplate(ctx context.Context, text, recipient string) (string, error) {
+	name, ok := templateName(text)
+	if !ok {
+		return text, nil
+	}
+
+	template, err := s.store.Template(ctx, name)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return text, nil
+		}
+		return "", errors.Wrap(err, "failed to get template")
+	}
+
+	return fillTemplate(template, recipient, time.Now().In(s.location(ctx))), nil
+}
+
+// templateName returns the name of the template that the message names, if
+// the message consists of only "/name".
+func templateName(text string) (string, bool) {
+	name, ok := strings.CutPrefix(strings.TrimSpace(text), templatePrefix)
+	if !ok || name == "" || strings.ContainsAny(name, " \t\n") {
+		return "", false
+	}
+	return name, true
+}
+
+// fillTemplate replaces the {name} variable in the template with the given
+// recipient name and {time} with the time of now.
+func fillTemplate(template, recipient string, now time.Time) string {
+	replacer := strings.NewReplacer(
+		"{name}", recipient,
+		"{time}", now.Format("15:04"),
+	)
+	return replacer.Replace(template)
+}
+
+// SetTemplates replaces all templates with the given ones, each formatted as
+// the name and the text separated by a tab.
+func (s *Session) SetTemplates(ctx context.Context, values []string) error {
+	templates := make(map[string]string, len(values))
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		name, text, ok := strings.Cut(value, "\t")
+		name = strings.TrimPrefix(strings.TrimSpace(name), templatePrefix)
+		text = strings.TrimSpace(text)
+		if !ok || text == "" {
+			return fmt.Errorf("template %q has no text", name)
+		}
+		if _, ok := templateName(templatePrefix + name); !ok {
+			return fmt.Errorf("invalid template name %q", name)
+		}
+		templates[name] = text
+	}
+
+	old, err := s.store.Templates(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get templates")
+	}
+
+	for name := range old {
+		if _, ok := templates[name]; ok {
+			continue
+		}
+		if err := s.store.RemoveTemplate(ctx, name); err != nil && !errors.Is(err, store.ErrNotFound) {
+			return errors.Wrapf(err, "failed to remove template %q", name)
+		}
+	}
+
+	for _, name := range sortedKeys(templates) {
+		if err := s.store.SetTemplate(ctx, name, templates[name]); err != nil {
+			return errors.Wrapf(err, "failed to set template %q", name)
+		}
+	}
+
+	return nil
+}
+
+func (s *Session) executeTemplate(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	action, rest, _ := strings.Cut(strings.TrimSpace(args["setting"]), " ")
+	name, text, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	name = strings.TrimPrefix(name, templatePrefix)
+	text = strings.TrimSpace(text)
+
+	switch action {
+	case "", "list":
+		templates, err := s.store.Templates(ctx)
+		if err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		if len(templates) == 0 {
+			return twicmd.StatusResponse("No templates.")
+		}
+
+		var buf strings.Builder
+		buf.WriteString("Templates:\n")
+		for _, name := range sortedKeys(templates) {
+			fmt.Fprintf(&buf, "%s%s: %q\n", templatePrefix, name, templates[name])
+		}
+		return twicmd.TextResponse(strings.TrimSuffix(buf.String(), "\n"))
+
+	case "add":
+		if name == "" || text == "" {
+			return twicmd.StatusResponse("usage: template add <name> <text>")
+		}
+
+		// Allow the text to be quoted.
+		if unquoted, err := strconv.Unquote(text); err == nil {
+			text = unquoted
+		}
+
+		if err := s.store.SetTemplate(ctx, name, text); err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		response := fmt.Sprintf("Added template %s%s.", templatePrefix, name)
+		return twicmd.TextResponse(response)
+
+	case "remove":
+		if name == "" {
+			return twicmd.StatusResponse("usage: template remove <name>")
+		}
+
+		if err := s.store.RemoveTemplate(ctx, name); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return twicmd.StatusResponse(fmt.Sprintf("no template named %q", name))
+			}
+			return s.internalErrorResponse(req, err)
+		}
+
+		response := fmt.Sprintf("Removed template %s%s.", templatePrefix, name)
+		return twicmd.TextResponse(response)
+
+	default:
+		return twicmd.StatusResponse(fmt.Sprintf("unknown template action %q", action))
+	}
+}
@@ -0,0 +1,57 @@
+package bot
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTemplateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		template string
+		ok       bool
+	}{
+		{"template", "/omw", "omw", true},
+		{"surrounding space", "  /omw\n", "omw", true},
+		{"plain text", "on my way", "", false},
+		{"bare slash", "/", "", false},
+		{"with text", "/shrug me", "", false},
+		{"slash in middle", "see /omw", "", false},
+		{"path", "/etc/hosts", "etc/hosts", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			template, ok := templateName(test.text)
+			if template != test.template || ok != test.ok {
+				t.Errorf("templateName(%q) = (%q, %v), want (%q, %v)",
+					test.text, template, ok, test.template, test.ok)
+			}
+		})
+	}
+}
+
+func TestFillTemplate(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 5, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	tests := []struct {
+		name     string
+		template string
+		filled   string
+	}{
+		{"plain", "on my way", "on my way"},
+		{"name", "hey {name}!", "hey alice!"},
+		{"time", "left at {time}", "left at 09:05"},
+		{"both twice", "{name} {time} {name} {time}", "alice 09:05 alice 09:05"},
+		{"unknown variable", "{eta}", "{eta}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if filled := fillTemplate(test.template, "alice", now); filled != test.filled {
+				t.Errorf("fillTemplate(%q) = %q, want %q", test.template, filled, test.filled)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/diamondburned/ningen/v3"
+	"github.com/pkg/errors"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// isThread returns true if the channel is a thread, including forum posts.
+func isThread(ch *discord.Channel) bool {
+	switch ch.Type {
+	case discord.GuildAnnouncementThread, discord.GuildPublicThread, discord.GuildPrivateThread:
+		return true
+	default:
+		return false
+	}
+}
+
+// activeThreads returns the threads in the guild that aren't archived. If
+// guildID is invalid, then threads in all guilds are returned.
+func activeThreads(state *ningen.State, guildID discord.GuildID) []discord.Channel {
+	var guildIDs []discord.GuildID
+	if guildID.IsValid() {
+		guildIDs = []discord.GuildID{guildID}
+	} else {
+		guilds, _ := state.Offline().Guilds()
+		for _, guild := range guilds {
+			guildIDs = append(guildIDs, guild.ID)
+		}
+	}
+
+	var threads []discord.Channel
+	for _, guildID := range guildIDs {
+		channels, _ := state.Offline().Channels(guildID, []discord.ChannelType{
+			discord.GuildAnnouncementThread,
+			discord.GuildPublicThread,
+			discord.GuildPrivateThread,
+		})
+		for _, ch := range channels {
+			if ch.ThreadMetadata != nil && ch.ThreadMetadata.Archived {
+				continue
+			}
+			threads = append(threads, ch)
+		}
+	}
+
+	return threads
+}
+
+func (s *Session) executeThreads(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	parent, err := s.searchParentChannel(args["channel"])
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	threads := filterSlice(activeThreads(s.State, parent.GuildID), func(ch discord.Channel) bool {
+		return ch.ParentID == parent.ID
+	})
+	if len(threads) == 0 {
+		return twicmd.StatusResponse(fmt.Sprintf("No active threads in #%s.", parent.Name))
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Active threads in #%s:\n", parent.Name)
+	for _, thread := range threads {
+		fmt.Fprintf(&buf, "- %s", thread.Name)
+		if thread.MessageCount > 0 {
+			fmt.Fprintf(&buf, " (%d messages)", thread.MessageCount)
+		}
+		buf.WriteByte('\n')
+	}
+	return twicmd.TextResponse(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// searchParentChannel searches all guilds for a channel that can have threads.
+func (s *Session) searchParentChannel(search string) (*discord.Channel, error) {
+	if search == "" {
+		return nil, errors.New("you must specify a channel")
+	}
+
+	guilds, err := s.State.Offline().Guilds()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get list of guilds: %w", err)
+	}
+
+	var channels []discord.Channel
+	for _, guild := range guilds {
+		guildChannels, _ := s.State.Offline().Channels(guild.ID, []discord.ChannelType{
+			discord.GuildText,
+			discord.GuildAnnouncement,
+			discord.GuildForum,
+		})
+		channels = append(channels, guildChannels...)
+	}
+
+	channel := matchChannel(channels, search)
+	if channel == nil {
+		return nil, errors.New("no such channel")
+	}
+	return channel, nil
+}
@@ -2,6 +2,7 @@ package bot
 
 import (
 	"log/slog"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -80,6 +81,39 @@ func (t *messageThrottler) AddMessage(id discord.MessageID, delayDuration time.D
 	}
 }
 
+// SendNow adds a message to the queue and dispatches the whole queue right
+// away, skipping any delay.
+func (t *messageThrottler) SendNow(id discord.MessageID) {
+	t.queueMu.Lock()
+	queue := append(t.queue, id)
+	t.queue = nil
+	t.queueMu.Unlock()
+
+	// Stop the pending job, since we've already stolen its queue.
+	if old := t.stop.Swap(nil); old != nil {
+		select {
+		case *old <- struct{}{}:
+			t.logger.Debug("stopped throttler job")
+		default:
+		}
+	}
+
+	t.wg.Add(1)
+	go func() {
+		t.send(t.chID, queue)
+		t.wg.Done()
+	}()
+}
+
+// RemoveMessage removes a message from the queue if it hasn't been sent yet.
+func (t *messageThrottler) RemoveMessage(id discord.MessageID) {
+	t.queueMu.Lock()
+	t.queue = slices.DeleteFunc(t.queue, func(queued discord.MessageID) bool {
+		return queued == id
+	})
+	t.queueMu.Unlock()
+}
+
 // DelaySending adds into the current delay time. It delays the callback to
 // allow the queue to accumulate more messages.
 func (t *messageThrottler) DelaySending(delayDuration time.Duration) {
@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// location returns the time zone that the user gives times of day in. The
+// server's time zone is used if they never set one.
+func (s *Session) location(ctx context.Context) *time.Location {
+	name, err := s.store.Timezone(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to get timezone",
+			"err", err,
+			*s.logAttrs.Load())
+		return time.Local
+	}
+
+	if name == "" {
+		return time.Local
+	}
+
+	loc, err := loadTimezone(name)
+	if err != nil {
+		s.logger.Error(
+			"failed to load timezone",
+			"timezone", name,
+			"err", err,
+			*s.logAttrs.Load())
+		return time.Local
+	}
+
+	return loc
+}
+
+// loadTimezone loads a time zone by its IANA name, such as "America/New_York".
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("you must specify a timezone like America/New_York")
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q, use a name like America/New_York", name)
+	}
+
+	return loc, nil
+}
+
+// SetTimezone sets the time zone that the user gives times of day in by its
+// IANA name.
+func (s *Session) SetTimezone(ctx context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if _, err := loadTimezone(name); err != nil {
+		return err
+	}
+
+	if err := s.store.SetTimezone(ctx, name); err != nil {
+		return errors.Wrap(err, "failed to set timezone")
+	}
+
+	return nil
+}
+
+func (s *Session) executeTimezone(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	name := strings.TrimSpace(args["timezone"])
+	if name == "" {
+		loc := s.location(ctx)
+		now := time.Now().In(loc).Format("Jan 2 15:04")
+
+		if loc == time.Local {
+			return twicmd.TextResponse(fmt.Sprintf(
+				"You haven't set a timezone, so the server's is used, where it is %s now.", now))
+		}
+		return twicmd.TextResponse(fmt.Sprintf("Your timezone is %s, where it is %s now.", loc, now))
+	}
+
+	if _, err := loadTimezone(name); err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	if err := s.store.SetTimezone(ctx, name); err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	return twicmd.TextResponse(fmt.Sprintf("Set your timezone to %s.", name))
+}
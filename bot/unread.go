@@ -0,0 +1,79 @@
+package bot
+
+import (
+	"github.com/diamondburned/arikawa/v3/discord"
+)
+
+type unreadChannel struct {
+	discord.Channel
+	UnreadCount int
+}
+
+// unreadPrivateChannels returns the unmuted private channels that have unread
+// messages.
+func (s *Session) unreadPrivateChannels() ([]unreadChannel, error) {
+	dms, err := s.State.Cabinet.PrivateChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	var unreads []unreadChannel
+
+	for _, dm := range dms {
+		if s.State.ChannelIsMuted(dm.ID, true) {
+			continue
+		}
+
+		count := s.State.ChannelCountUnreads(dm.ID)
+		if count > 0 {
+			unreads = append(unreads, unreadChannel{
+				Channel:     dm,
+				UnreadCount: count,
+			})
+		}
+	}
+
+	return unreads, nil
+}
+
+type mentionedChannel struct {
+	discord.Channel
+	MentionCount int
+	LastReadID   discord.MessageID
+}
+
+// mentionedGuildChannels returns the channels in the given guild that have
+// unread mentions. Mentions override mutes, so muted channels are included.
+func (s *Session) mentionedGuildChannels(guildID discord.GuildID) []mentionedChannel {
+	channels, err := s.State.Cabinet.Channels(guildID)
+	if err != nil {
+		return nil
+	}
+
+	var mentioned []mentionedChannel
+
+	for _, ch := range channels {
+		readState := s.State.ReadState.ReadState(ch.ID)
+		if readState == nil || readState.MentionCount == 0 {
+			continue
+		}
+
+		mentioned = append(mentioned, mentionedChannel{
+			Channel:      ch,
+			MentionCount: readState.MentionCount,
+			LastReadID:   readState.LastMessageID,
+		})
+	}
+
+	return mentioned
+}
+
+// truncateText truncates the given text to at most max runes, adding an
+// ellipsis if it was truncated.
+func truncateText(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
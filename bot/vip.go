@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// reloadVIPUsers reloads the VIP users from the store.
+func (s *Session) reloadVIPUsers(ctx context.Context) {
+	userIDs, err := s.store.VIPUsers(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load VIP users",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	vips := make(map[discord.UserID]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		vips[id] = struct{}{}
+	}
+
+	s.vipUsers.Store(&vips)
+}
+
+// isVIP returns true if messages from the given user should bypass mutes and
+// delays.
+func (s *Session) isVIP(userID discord.UserID) bool {
+	vips := s.vipUsers.Load()
+	if vips == nil {
+		return false
+	}
+	_, ok := (*vips)[userID]
+	return ok
+}
+
+func (s *Session) executeVIP(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	return s.searchUserThen(ctx, args["user"], func(ctx context.Context, userID discord.UserID) *twicmdproto.ExecuteResponse {
+		if err := s.store.AddVIPUser(ctx, userID); err != nil {
+			return s.internalErrorResponse(req, err)
+		}
+
+		s.reloadVIPUsers(ctx)
+
+		response := fmt.Sprintf(
+			"Added %s as a VIP. Their messages will be sent right away, even when muted.",
+			UserName(s.State, userID))
+		return twicmd.TextResponse(response)
+	})
+}
+
+func (s *Session) executeUnvip(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	return s.searchUserThen(ctx, args["user"], func(ctx context.Context, userID discord.UserID) *twicmdproto.ExecuteResponse {
+		if err := s.store.RemoveVIPUser(ctx, userID); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return twicmd.StatusResponse("that user is not a VIP")
+			}
+			return s.internalErrorResponse(req, err)
+		}
+
+		s.reloadVIPUsers(ctx)
+
+		response := fmt.Sprintf("Removed %s from VIPs.", UserName(s.State, userID))
+		return twicmd.TextResponse(response)
+	})
+}
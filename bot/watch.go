@@ -0,0 +1,253 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/pkg/errors"
+	"github.com/twipi/twidiscord/store"
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+type watchRule struct {
+	store.WatchRule
+	re *regexp.Regexp
+}
+
+func compileWatchRule(rule store.WatchRule) (watchRule, error) {
+	compiled := watchRule{WatchRule: rule}
+	if rule.IsRegex {
+		re, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return compiled, err
+		}
+		compiled.re = re
+	}
+	return compiled, nil
+}
+
+func (r watchRule) matches(msg *discord.Message) bool {
+	if r.GuildID.IsValid() && r.GuildID != msg.GuildID {
+		return false
+	}
+	if r.ChannelID.IsValid() && r.ChannelID != msg.ChannelID {
+		return false
+	}
+	for _, text := range messageTexts(msg) {
+		if r.matchesText(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r watchRule) matchesText(text string) bool {
+	if r.re != nil {
+		return r.re.MatchString(text)
+	}
+	return strings.Contains(strings.ToLower(text), strings.ToLower(r.Pattern))
+}
+
+// messageTexts returns all the text in the message that watch rules match
+// against: its content and the title, description and fields of its embeds.
+func messageTexts(msg *discord.Message) []string {
+	texts := []string{msg.Content}
+	for _, embed := range msg.Embeds {
+		texts = append(texts, embed.Title, embed.Description)
+		for _, field := range embed.Fields {
+			texts = append(texts, field.Name, field.Value)
+		}
+	}
+	return texts
+}
+
+// parseWatchPattern parses the pattern given to the watch commands. Patterns
+// wrapped in slashes are regular expressions.
+func parseWatchPattern(pattern string) (store.WatchRule, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return store.WatchRule{}, errors.New("you must specify a keyword")
+	}
+
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		return store.WatchRule{
+			Pattern: pattern[1 : len(pattern)-1],
+			IsRegex: true,
+		}, nil
+	}
+
+	return store.WatchRule{Pattern: pattern}, nil
+}
+
+// reloadWatchRules reloads the keyword watch rules from the store.
+func (s *Session) reloadWatchRules(ctx context.Context) {
+	rules, err := s.store.WatchRules(ctx)
+	if err != nil {
+		s.logger.Error(
+			"failed to load watch rules",
+			"err", err,
+			*s.logAttrs.Load())
+		return
+	}
+
+	compiled := make([]watchRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compileWatchRule(rule)
+		if err != nil {
+			s.logger.Warn(
+				"ignoring invalid watch rule",
+				"rule_id", rule.ID,
+				"err", err,
+				*s.logAttrs.Load())
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.watchRules.Store(&compiled)
+}
+
+// matchesWatchRule returns true if the message matches any of the user's
+// keyword watch rules.
+func (s *Session) matchesWatchRule(msg *discord.Message) bool {
+	rules := s.watchRules.Load()
+	if rules == nil {
+		return false
+	}
+	for _, rule := range *rules {
+		if rule.matches(msg) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Session) executeWatch(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	rule, err := parseWatchPattern(args["pattern"])
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	return s.addWatchRule(ctx, req, rule)
+}
+
+func (s *Session) executeGuildWatch(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	rule, err := parseWatchPattern(args["pattern"])
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	guild, err := searchGuild(ctx, s.State, s.store, args["guild"])
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+	rule.GuildID = guild.ID
+
+	return s.addWatchRule(ctx, req, rule)
+}
+
+func (s *Session) executeChannelWatch(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	rule, err := parseWatchPattern(args["pattern"])
+	if err != nil {
+		return twicmd.StatusResponse(err.Error())
+	}
+
+	return s.searchChannelThen(ctx, args["guild"], args["channel"], func(ctx context.Context, r *channelSearchResult) *twicmdproto.ExecuteResponse {
+		rule.GuildID = r.Channel.GuildID
+		rule.ChannelID = r.Channel.ID
+		return s.addWatchRule(ctx, req, rule)
+	})
+}
+
+func (s *Session) addWatchRule(ctx context.Context, req *twicmdproto.ExecuteRequest, rule store.WatchRule) *twicmdproto.ExecuteResponse {
+	if _, err := compileWatchRule(rule); err != nil {
+		return twicmd.StatusResponse(fmt.Sprintf("invalid regular expression: %v", err))
+	}
+
+	id, err := s.store.AddWatchRule(ctx, rule)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+	rule.ID = id
+
+	s.reloadWatchRules(ctx)
+
+	return twicmd.TextResponse("Added watch rule " + s.watchRuleString(rule) + ".")
+}
+
+func (s *Session) executeUnwatch(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	args := twicmd.MapArguments(req.Command.Arguments)
+
+	id, err := strconv.ParseInt(args["id"], 10, 64)
+	if err != nil {
+		return twicmd.StatusResponse("invalid watch rule ID")
+	}
+
+	if err := s.store.RemoveWatchRule(ctx, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return twicmd.StatusResponse("no such watch rule")
+		}
+		return s.internalErrorResponse(req, err)
+	}
+
+	s.reloadWatchRules(ctx)
+
+	return twicmd.TextResponse(fmt.Sprintf("Removed watch rule %d.", id))
+}
+
+func (s *Session) executeWatches(ctx context.Context, req *twicmdproto.ExecuteRequest) *twicmdproto.ExecuteResponse {
+	rules, err := s.store.WatchRules(ctx)
+	if err != nil {
+		return s.internalErrorResponse(req, err)
+	}
+
+	if len(rules) == 0 {
+		return twicmd.StatusResponse("No watch rules.")
+	}
+
+	var buf strings.Builder
+	buf.WriteString("Watch rules:\n")
+	for _, rule := range rules {
+		buf.WriteString(s.watchRuleString(rule))
+		buf.WriteByte('\n')
+	}
+	return twicmd.TextResponse(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+// watchRuleString formats the watch rule for displaying to the user.
+func (s *Session) watchRuleString(rule store.WatchRule) string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%d: ", rule.ID)
+	if rule.IsRegex {
+		fmt.Fprintf(&buf, "/%s/", rule.Pattern)
+	} else {
+		fmt.Fprintf(&buf, "%q", rule.Pattern)
+	}
+
+	switch {
+	case rule.ChannelID.IsValid():
+		if ch, err := s.State.Cabinet.Channel(rule.ChannelID); err == nil {
+			fmt.Fprintf(&buf, " in #%s", ChannelName(ch, true))
+		} else {
+			fmt.Fprintf(&buf, " in %s", rule.ChannelID.Mention())
+		}
+		fallthrough
+	case rule.GuildID.IsValid():
+		if guild, err := s.State.Cabinet.Guild(rule.GuildID); err == nil {
+			fmt.Fprintf(&buf, " (%s)", guild.Name)
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/diamondburned/arikawa/v3/discord"
+	"github.com/twipi/twidiscord/store"
+)
+
+func TestWatchRuleMatches(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  store.WatchRule
+		msg   discord.Message
+		match bool
+	}{
+		{
+			name:  "keyword",
+			rule:  store.WatchRule{Pattern: "deploy"},
+			msg:   discord.Message{Content: "Starting the Deploy now"},
+			match: true,
+		},
+		{
+			name:  "keyword mismatch",
+			rule:  store.WatchRule{Pattern: "deploy"},
+			msg:   discord.Message{Content: "lunch?"},
+			match: false,
+		},
+		{
+			name:  "regex",
+			rule:  store.WatchRule{Pattern: `\bsev[12]\b`, IsRegex: true},
+			msg:   discord.Message{Content: "SEV1 declared"},
+			match: true,
+		},
+		{
+			name: "other guild",
+			rule: store.WatchRule{Pattern: "deploy", GuildID: 1},
+			msg: discord.Message{
+				GuildID: 2,
+				Content: "deploy",
+			},
+			match: false,
+		},
+		{
+			name: "other channel",
+			rule: store.WatchRule{Pattern: "deploy", ChannelID: 1},
+			msg: discord.Message{
+				ChannelID: 2,
+				Content:   "deploy",
+			},
+			match: false,
+		},
+		{
+			name: "bot embed title",
+			rule: store.WatchRule{Pattern: "firing", GuildID: 1},
+			msg: discord.Message{
+				GuildID: 1,
+				Author:  discord.User{Bot: true},
+				Embeds: []discord.Embed{
+					{Title: "[FIRING:1] HighLatency"},
+				},
+			},
+			match: true,
+		},
+		{
+			name: "bot embed field",
+			rule: store.WatchRule{Pattern: `^critical$`, IsRegex: true},
+			msg: discord.Message{
+				Author: discord.User{Bot: true},
+				Embeds: []discord.Embed{{
+					Title:  "Alert",
+					Fields: []discord.EmbedField{{Name: "Severity", Value: "Critical"}},
+				}},
+			},
+			match: true,
+		},
+		{
+			name: "bot embed mismatch",
+			rule: store.WatchRule{Pattern: "firing"},
+			msg: discord.Message{
+				Author: discord.User{Bot: true},
+				Embeds: []discord.Embed{
+					{Title: "[RESOLVED] HighLatency", Description: "all good"},
+				},
+			},
+			match: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := compileWatchRule(test.rule)
+			if err != nil {
+				t.Fatalf("failed to compile rule: %v", err)
+			}
+			if match := rule.matches(&test.msg); match != test.match {
+				t.Errorf("matches() = %v, want %v", match, test.match)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Package focusparser provides a Twicmd parser that turns texts that aren't
+// slash commands into messages for the channel that is focused on in
+// twidiscord. Import it into twid for its side effect of registering the
+// "twidiscord_focus" parser module, and list that module before the slash
+// parser so that slash commands still work.
+//
+// Parsers aren't told who sent a text, so the parser only claims texts while
+// an account is focused, and leaves them to the next parser otherwise.
+// twidiscord doesn't reply to texts that it gets from anyone else meanwhile.
+package focusparser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/proto/out/twismsproto"
+	"github.com/twipi/twipi/twicmd"
+	"github.com/twipi/twipi/twid"
+)
+
+// Command is the name of the command that texts are parsed into. It isn't
+// listed in the service description, since it's never typed out.
+const Command = "focused_text"
+
+// StatusCommand is the name of the command that the parser executes to ask
+// twidiscord whether any account is focused. twidiscord replies with a
+// non-empty text if one is.
+const StatusCommand = "focus_status"
+
+// DefaultService is the name of the twidiscord service if none is configured.
+const DefaultService = "discord"
+
+// Config is the configuration of the parser.
+type Config struct {
+	// Service is the name that the twidiscord service is registered under.
+	Service string `json:"service"`
+}
+
+func init() {
+	twid.RegisterTwicmdParser(twid.TwicmdParser{
+		Name: "twidiscord_focus",
+		New: func(cfg json.RawMessage, logger *slog.Logger) (twicmd.CommandParser, error) {
+			var config Config
+			if err := json.Unmarshal(cfg, &config); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal focus parser config: %w", err)
+			}
+			return NewParser(config.Service, logger), nil
+		},
+	})
+}
+
+// Parser is a command parser that parses texts into messages for the focused
+// channel.
+type Parser struct {
+	service string
+	logger  *slog.Logger
+}
+
+var _ twicmd.CommandParser = (*Parser)(nil)
+
+// NewParser creates a new Parser for the twidiscord service with the given
+// name. DefaultService is used if the name is empty.
+func NewParser(service string, logger *slog.Logger) *Parser {
+	if service == "" {
+		service = DefaultService
+	}
+	return &Parser{service: service, logger: logger}
+}
+
+// Name implements [twicmd.CommandParser].
+func (p *Parser) Name() string {
+	return "twidiscord_focus"
+}
+
+// Parse implements [twicmd.CommandParser]. Slash commands, and every text
+// while no account is focused, are left to the next parser. Texts like /omw
+// that start with a slash but don't name a service are parsed, so that
+// twidiscord can expand them as templates or send them as they are.
+func (p *Parser) Parse(ctx context.Context, lookup *twicmd.ServiceLookup, body *twismsproto.MessageBody) (*twicmdproto.Command, error) {
+	if body.Text == nil {
+		return nil, nil
+	}
+
+	text := strings.TrimSpace(body.Text.Text)
+	if text == "" || isSlashCommand(lookup, text) {
+		return nil, nil
+	}
+
+	if !p.focused(ctx, lookup) {
+		return nil, nil
+	}
+
+	return &twicmdproto.Command{
+		Service: p.service,
+		Command: Command,
+		Arguments: []*twicmdproto.CommandArgument{
+			{Name: "text", Value: text},
+		},
+	}, nil
+}
+
+// isSlashCommand returns whether the text is a command for the slash parser,
+// which are written as /<service> <command>.
+func isSlashCommand(lookup *twicmd.ServiceLookup, text string) bool {
+	words := strings.Fields(text)
+	if len(words) == 0 || !strings.HasPrefix(words[0], "/") {
+		return false
+	}
+	_, ok := lookup.Service(strings.TrimPrefix(words[0], "/"))
+	return ok
+}
+
+// focused asks the twidiscord service whether any account is focused. Errors
+// are logged rather than returned, so that texts still reach the next parser
+// while twidiscord is unreachable.
+func (p *Parser) focused(ctx context.Context, lookup *twicmd.ServiceLookup) bool {
+	service, ok := lookup.Service(p.service)
+	if !ok {
+		return false
+	}
+
+	resp, err := service.Execute(ctx, &twicmdproto.ExecuteRequest{
+		Command: &twicmdproto.Command{
+			Service: p.service,
+			Command: StatusCommand,
+		},
+	})
+	if err != nil {
+		p.logger.Warn(
+			"failed to check the focus status",
+			"service", p.service,
+			"err", err)
+		return false
+	}
+
+	return resp.GetText() != ""
+}
@@ -0,0 +1,127 @@
+package focusparser
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/twipi/twipi/proto/out/twicmdproto"
+	"github.com/twipi/twipi/proto/out/twismsproto"
+	"github.com/twipi/twipi/twicmd"
+)
+
+// statusService is a twidiscord service that only answers StatusCommand.
+type statusService struct {
+	focused bool
+}
+
+var _ twicmd.Service = (*statusService)(nil)
+
+func (s *statusService) Name() string { return DefaultService }
+
+func (s *statusService) Service(ctx context.Context) (*twicmdproto.Service, error) {
+	return &twicmdproto.Service{Name: DefaultService}, nil
+}
+
+func (s *statusService) Execute(ctx context.Context, req *twicmdproto.ExecuteRequest) (*twicmdproto.ExecuteResponse, error) {
+	if req.Command.Command != StatusCommand || !s.focused {
+		return nil, nil
+	}
+	return twicmd.TextResponse("focused"), nil
+}
+
+func (s *statusService) SubscribeMessages(chan<- *twismsproto.Message, *twismsproto.MessageFilters) {}
+
+func (s *statusService) UnsubscribeMessages(chan<- *twismsproto.Message) {}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    *twismsproto.MessageBody
+		focused bool
+		text    string // empty if the text is left to the next parser
+	}{
+		{
+			name:    "text",
+			body:    &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "on my way"}},
+			focused: true,
+			text:    "on my way",
+		},
+		{
+			name:    "trimmed",
+			body:    &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "  ok\n"}},
+			focused: true,
+			text:    "ok",
+		},
+		{
+			name: "not focused",
+			body: &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "on my way"}},
+		},
+		{
+			name:    "slash command",
+			body:    &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "/discord unfocus"}},
+			focused: true,
+		},
+		{
+			name:    "indented slash command",
+			body:    &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: " /discord unfocus"}},
+			focused: true,
+		},
+		{
+			name:    "template",
+			body:    &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "/omw"}},
+			focused: true,
+			text:    "/omw",
+		},
+		{
+			name:    "slash text",
+			body:    &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "/shrug ok"}},
+			focused: true,
+			text:    "/shrug ok",
+		},
+		{
+			name: "template while not focused",
+			body: &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "/omw"}},
+		},
+		{
+			name:    "blank",
+			body:    &twismsproto.MessageBody{Text: &twismsproto.TextBody{Text: "   "}},
+			focused: true,
+		},
+		{
+			name:    "no text",
+			body:    &twismsproto.MessageBody{},
+			focused: true,
+		},
+	}
+
+	p := NewParser("", slog.Default())
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lookup := twicmd.NewServiceLookup()
+			lookup.Register(&statusService{focused: test.focused})
+
+			cmd, err := p.Parse(context.Background(), lookup, test.body)
+			if err != nil {
+				t.Fatalf("Parse() failed: %v", err)
+			}
+
+			if test.text == "" {
+				if cmd != nil {
+					t.Errorf("Parse() = %v, want nil", cmd)
+				}
+				return
+			}
+
+			if cmd == nil {
+				t.Fatalf("Parse() = nil, want a command")
+			}
+			if cmd.Service != DefaultService || cmd.Command != Command {
+				t.Errorf("Parse() parsed command %s %s, want %s %s", cmd.Service, cmd.Command, DefaultService, Command)
+			}
+			if len(cmd.Arguments) != 1 || cmd.Arguments[0].Name != "text" || cmd.Arguments[0].Value != test.text {
+				t.Errorf("Parse() parsed arguments %v, want text=%q", cmd.Arguments, test.text)
+			}
+		})
+	}
+}
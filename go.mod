@@ -14,6 +14,8 @@ require (
 	github.com/xhit/go-str2duration/v2 v2.1.0
 	github.com/yuin/goldmark v1.5.2
 	golang.org/x/sync v0.6.0
+	golang.org/x/text v0.14.0
+	golang.org/x/time v0.5.0
 	google.golang.org/protobuf v1.33.0
 	libdb.so/hserve v0.0.0-20230404043009-95e112a6e0a5
 	libdb.so/lazymigrate v0.0.0-20240221022551-223d9b492a64
@@ -42,7 +44,6 @@ require (
 	go4.org v0.0.0-20200411211856-f5505b9728dd // indirect
 	golang.org/x/net v0.24.0 // indirect
 	golang.org/x/sys v0.19.0 // indirect
-	golang.org/x/time v0.5.0 // indirect
 	libdb.so/ctxt v0.0.0-20240229093153-2db38a5d3c12 // indirect
 	libdb.so/hrt v0.0.0-20240421082846-86ff8f6e2d0e // indirect
 	libdb.so/hrtclient v0.0.0-20240421080023-4dbf5f693ee7 // indirect
@@ -10,6 +10,9 @@ import (
 	"path/filepath"
 	"strings"
 
+	// Users may set any timezone, even if the system has no tzdata.
+	_ "time/tzdata"
+
 	"github.com/spf13/pflag"
 	"github.com/twipi/twidiscord/service"
 	"github.com/twipi/twidiscord/store"
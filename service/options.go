@@ -16,6 +16,21 @@ type optionFunc func(s *Service, ctx context.Context, phoneNumber string) (*twic
 var optionFuncs = []optionFunc{
 	(*Service).optionDiscordToken,
 	(*Service).optionNicknames,
+	(*Service).optionVIPs,
+	(*Service).optionBlockRules,
+	(*Service).optionDeliveryMode,
+	(*Service).optionTemplates,
+	(*Service).optionTimezone,
+}
+
+type applyFunc func(s *Service, ctx context.Context, phoneNumber string, value *twicmdcfgpb.OptionValue) error
+
+// applyFuncs are the functions that apply the options that can be changed,
+// by option ID.
+var applyFuncs = map[string]applyFunc{
+	"delivery_mode": (*Service).applyDeliveryMode,
+	"templates":     (*Service).applyTemplates,
+	"timezone":      (*Service).applyTimezone,
 }
 
 func (s *Service) optionDiscordToken(ctx context.Context, phoneNumber string) (*twicmdcfgpb.OptionValue, error) {
@@ -121,6 +136,163 @@ func (s *Service) optionNicknames(ctx context.Context, phoneNumber string) (*twi
 	}, nil
 }
 
+func (s *Service) optionVIPs(ctx context.Context, phoneNumber string) (*twicmdcfgpb.OptionValue, error) {
+	b, ok := s.knownBots.Load(phoneNumber)
+	if !ok {
+		return nil, fmt.Errorf("account not ready, try again later")
+	}
+
+	account, err := s.store.Account(ctx, phoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("no account found")
+	}
+
+	userIDs, err := account.VIPUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get VIP users: %w", err)
+	}
+
+	values := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		values[i] = bot.UserName(b.Session.State, id) + "\t" + id.String()
+	}
+	slices.Sort(values)
+
+	return &twicmdcfgpb.OptionValue{
+		Id: "vips",
+		Value: &twicmdcfgpb.OptionValue_StringList{
+			StringList: &twicmdcfgpb.StringListValue{
+				Values: values,
+			},
+		},
+	}, nil
+}
+
+func (s *Service) optionBlockRules(ctx context.Context, phoneNumber string) (*twicmdcfgpb.OptionValue, error) {
+	b, ok := s.knownBots.Load(phoneNumber)
+	if !ok {
+		return nil, fmt.Errorf("account not ready, try again later")
+	}
+
+	account, err := s.store.Account(ctx, phoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("no account found")
+	}
+
+	rules, err := account.BlockRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block rules: %w", err)
+	}
+
+	values := make([]string, len(rules))
+	for i, rule := range rules {
+		values[i] = fmt.Sprintf(
+			"%d\t%s\t%s",
+			rule.ID, rule.Kind, bot.BlockRuleValue(b.Session.State, rule))
+	}
+
+	return &twicmdcfgpb.OptionValue{
+		Id: "block_rules",
+		Value: &twicmdcfgpb.OptionValue_StringList{
+			StringList: &twicmdcfgpb.StringListValue{
+				Values: values,
+			},
+		},
+	}, nil
+}
+
+func (s *Service) optionDeliveryMode(ctx context.Context, phoneNumber string) (*twicmdcfgpb.OptionValue, error) {
+	account, err := s.store.Account(ctx, phoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("no account found")
+	}
+
+	settings, err := account.DeliverySettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get delivery settings: %w", err)
+	}
+
+	return &twicmdcfgpb.OptionValue{
+		Id: "delivery_mode",
+		Value: &twicmdcfgpb.OptionValue_String_{
+			String_: bot.DeliveryModeValue(settings),
+		},
+	}, nil
+}
+
+func (s *Service) applyDeliveryMode(ctx context.Context, phoneNumber string, value *twicmdcfgpb.OptionValue) error {
+	b, ok := s.knownBots.Load(phoneNumber)
+	if !ok {
+		return fmt.Errorf("account not ready, try again later")
+	}
+
+	return b.SetDeliveryMode(ctx, value.GetString_())
+}
+
+func (s *Service) optionTemplates(ctx context.Context, phoneNumber string) (*twicmdcfgpb.OptionValue, error) {
+	account, err := s.store.Account(ctx, phoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("no account found")
+	}
+
+	templates, err := account.Templates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get templates: %w", err)
+	}
+
+	values := make([]string, 0, len(templates))
+	for name, text := range templates {
+		values = append(values, name+"\t"+text)
+	}
+	slices.Sort(values)
+
+	return &twicmdcfgpb.OptionValue{
+		Id: "templates",
+		Value: &twicmdcfgpb.OptionValue_StringList{
+			StringList: &twicmdcfgpb.StringListValue{
+				Values: values,
+			},
+		},
+	}, nil
+}
+
+func (s *Service) applyTemplates(ctx context.Context, phoneNumber string, value *twicmdcfgpb.OptionValue) error {
+	b, ok := s.knownBots.Load(phoneNumber)
+	if !ok {
+		return fmt.Errorf("account not ready, try again later")
+	}
+
+	return b.SetTemplates(ctx, value.GetStringList().GetValues())
+}
+
+func (s *Service) optionTimezone(ctx context.Context, phoneNumber string) (*twicmdcfgpb.OptionValue, error) {
+	account, err := s.store.Account(ctx, phoneNumber)
+	if err != nil {
+		return nil, fmt.Errorf("no account found")
+	}
+
+	name, err := account.Timezone(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timezone: %w", err)
+	}
+
+	return &twicmdcfgpb.OptionValue{
+		Id: "timezone",
+		Value: &twicmdcfgpb.OptionValue_String_{
+			String_: name,
+		},
+	}, nil
+}
+
+func (s *Service) applyTimezone(ctx context.Context, phoneNumber string, value *twicmdcfgpb.OptionValue) error {
+	b, ok := s.knownBots.Load(phoneNumber)
+	if !ok {
+		return fmt.Errorf("account not ready, try again later")
+	}
+
+	return b.SetTimezone(ctx, value.GetString_())
+}
+
 type channelNickItem struct {
 	Nickname  string
 	ChannelID discord.ChannelID
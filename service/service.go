@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"sync"
+	"time"
 
 	_ "embed"
 
@@ -33,6 +35,10 @@ var service = (func() *twicmdproto.Service {
 	return service
 })()
 
+// scheduleInterval is how often scheduled messages and reminders are checked
+// for sending.
+const scheduleInterval = 30 * time.Second
+
 // Service is the main handler that binds Twipi and Discord.
 type Service struct {
 	store     store.Store
@@ -100,13 +106,37 @@ func (s *Service) Service(ctx context.Context) (*twicmdproto.Service, error) {
 
 // Execute implements [twicmd.Service].
 func (s *Service) Execute(ctx context.Context, req *twicmdproto.ExecuteRequest) (*twicmdproto.ExecuteResponse, error) {
+	// The focusparser package asks for this without a message, since it
+	// isn't told who sent the text that it parses.
+	if req.Command.Command == "focus_status" {
+		return s.focusStatus(), nil
+	}
+
 	bb, ok := s.knownBots.Load(req.Message.From)
 	if !ok {
+		if req.Command.Command == "focused_text" {
+			// Texts are parsed as focused texts from anyone while some
+			// account is focused. Don't answer strangers.
+			return nil, nil
+		}
 		return twicmd.StatusResponse("your account is not ready yet"), nil
 	}
 	return bb.Execute(ctx, req)
 }
 
+// focusStatus replies with a non-empty text if any account is focused.
+func (s *Service) focusStatus() *twicmdproto.ExecuteResponse {
+	var focused bool
+	s.knownBots.Range(func(_ string, bb startedBot) bool {
+		focused = bb.Focused()
+		return !focused
+	})
+	if !focused {
+		return nil
+	}
+	return twicmd.TextResponse("focused")
+}
+
 // Start connects all the accounts. It blocks until ctx is canceled.
 func (s *Service) Start(ctx context.Context) error {
 	errg, ctx := errgroup.WithContext(ctx)
@@ -156,6 +186,43 @@ func (s *Service) Start(ctx context.Context) error {
 		}
 	})
 
+	errg.Go(func() error {
+		ticker := time.NewTicker(scheduleInterval)
+		defer ticker.Stop()
+
+		var wg sync.WaitGroup
+		defer wg.Wait()
+
+		sending := xsync.NewMapOf[string, struct{}]()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+
+			// Each account sends on its own, so that one that is slow to send
+			// doesn't hold up the others. An account that is still sending
+			// from the last check is skipped.
+			s.knownBots.Range(func(number string, b startedBot) bool {
+				if _, busy := sending.LoadOrStore(number, struct{}{}); busy {
+					return true
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer sending.Delete(number)
+
+					b.SendScheduled(ctx)
+					b.SendReminders(ctx)
+				}()
+				return true
+			})
+		}
+	})
+
 	errg.Go(func() error {
 		accounts, err := s.store.Accounts(ctx)
 		if err != nil {
@@ -233,6 +300,34 @@ func (s *Service) ConfigurationValues(ctx context.Context, req *twicmdcfgpb.Opti
 }
 
 // ApplyConfigurationValues implements [twicmd.ConfigurableService].
-func (s *Service) ApplyConfigurationValues(context.Context, *twicmdcfgpb.ApplyRequest) (*twicmdcfgpb.ApplyResponse, error) {
-	return nil, fmt.Errorf("not implemented")
+func (s *Service) ApplyConfigurationValues(ctx context.Context, req *twicmdcfgpb.ApplyRequest) (*twicmdcfgpb.ApplyResponse, error) {
+	var errs []*twicmdcfgpb.ApplyError
+	for _, value := range req.Values {
+		apply, ok := applyFuncs[value.Id]
+		if !ok {
+			errs = append(errs, &twicmdcfgpb.ApplyError{
+				OptionId: value.Id,
+				Message:  "this option can't be changed here",
+			})
+			continue
+		}
+
+		if err := apply(s, ctx, req.PhoneNumber, value); err != nil {
+			s.logger.Debug(
+				"failed to apply configuration value",
+				"user_number", req.PhoneNumber,
+				"option_id", value.Id,
+				"err", err)
+
+			errs = append(errs, &twicmdcfgpb.ApplyError{
+				OptionId: value.Id,
+				Message:  err.Error(),
+			})
+		}
+	}
+
+	return &twicmdcfgpb.ApplyResponse{
+		Success: len(errs) == 0,
+		Errors:  errs,
+	}, nil
 }
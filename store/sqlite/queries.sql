@@ -26,3 +26,240 @@ SELECT channel_id FROM channel_nicknames WHERE user_number = ? AND nickname = ?
 
 -- name: SetChannelNickname :exec
 REPLACE INTO channel_nicknames (user_number, channel_id, nickname) VALUES (?, ?, ?);
+
+-- name: WatchRules :many
+SELECT id, pattern, is_regex, guild_id, channel_id FROM watch_rules WHERE user_number = ?;
+
+-- name: AddWatchRule :one
+INSERT INTO watch_rules (user_number, pattern, is_regex, guild_id, channel_id)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id;
+
+-- name: RemoveWatchRule :execrows
+DELETE FROM watch_rules WHERE user_number = ? AND id = ?;
+
+-- name: VipUsers :many
+SELECT user_id FROM vip_users WHERE user_number = ?;
+
+-- name: AddVipUser :exec
+REPLACE INTO vip_users (user_number, user_id) VALUES (?, ?);
+
+-- name: RemoveVipUser :execrows
+DELETE FROM vip_users WHERE user_number = ? AND user_id = ?;
+
+-- name: BlockRules :many
+SELECT id, kind, value, guild_id FROM block_rules WHERE user_number = ?;
+
+-- name: AddBlockRule :one
+INSERT INTO block_rules (user_number, kind, value, guild_id)
+	VALUES (?, ?, ?, ?)
+	RETURNING id;
+
+-- name: RemoveBlockRule :execrows
+DELETE FROM block_rules WHERE user_number = ? AND id = ?;
+
+-- name: SmsBudget :one
+SELECT daily_segments, monthly_segments, hourly_messages, over_budget FROM sms_budgets
+	WHERE user_number = ?
+	LIMIT 1;
+
+-- name: SetSmsBudget :exec
+REPLACE INTO sms_budgets (user_number, daily_segments, monthly_segments, hourly_messages, over_budget)
+	VALUES (?, ?, ?, ?, ?);
+
+-- name: SmsUsage :many
+SELECT segments FROM sms_usage WHERE user_number = ? AND day >= ?;
+
+-- name: AddSmsUsage :exec
+INSERT INTO sms_usage (user_number, day, segments) VALUES (?, ?, ?)
+	ON CONFLICT (user_number, day) DO UPDATE SET segments = segments + excluded.segments;
+
+-- name: PruneSmsUsage :exec
+DELETE FROM sms_usage WHERE user_number = ? AND day < ?;
+
+-- name: HeldSms :many
+SELECT id, text FROM held_sms WHERE user_number = ? ORDER BY id ASC;
+
+-- name: AddHeldSms :one
+INSERT INTO held_sms (user_number, text) VALUES (?, ?) RETURNING id;
+
+-- name: RemoveHeldSms :execrows
+DELETE FROM held_sms WHERE user_number = ? AND id = ?;
+
+-- name: DeliverySettings :one
+SELECT mode, digest_interval, digest_times, notify_deletes FROM delivery_settings WHERE user_number = ? LIMIT 1;
+
+-- name: SetDeliverySettings :exec
+REPLACE INTO delivery_settings (user_number, mode, digest_interval, digest_times, notify_deletes)
+	VALUES (?, ?, ?, ?, ?);
+
+-- name: Focus :one
+SELECT channel_id, until FROM focus WHERE user_number = ? LIMIT 1;
+
+-- name: SetFocus :exec
+REPLACE INTO focus (user_number, channel_id, until) VALUES (?, ?, ?);
+
+-- name: ClearFocus :exec
+DELETE FROM focus WHERE user_number = ?;
+
+-- name: PendingMessages :many
+SELECT channel_id, message_id FROM pending_messages WHERE user_number = ? ORDER BY message_id;
+
+-- name: AddPendingMessage :exec
+REPLACE INTO pending_messages (user_number, channel_id, message_id) VALUES (?, ?, ?);
+
+-- name: RemovePendingMessage :exec
+DELETE FROM pending_messages WHERE user_number = ? AND message_id = ?;
+
+-- name: DigestEntries :many
+SELECT channel_id, message_id, author, content FROM digest_entries
+	WHERE user_number = ?
+	ORDER BY message_id;
+
+-- name: AddDigestEntry :exec
+REPLACE INTO digest_entries (user_number, channel_id, message_id, author, content) VALUES (?, ?, ?, ?, ?);
+
+-- name: RemoveDigestEntry :exec
+DELETE FROM digest_entries WHERE user_number = ? AND message_id = ?;
+
+-- name: ClearDigestEntries :exec
+DELETE FROM digest_entries WHERE user_number = ?;
+
+-- name: ForwardedMessage :one
+SELECT channel_id, message_id, forwarded_at, content_hash, author FROM forwarded_messages
+	WHERE user_number = ? AND message_id = ?;
+
+-- name: RecentForwardedMessage :one
+SELECT channel_id, message_id, forwarded_at, content_hash, author FROM forwarded_messages
+	WHERE user_number = ?
+	ORDER BY forwarded_at DESC, message_id DESC
+	LIMIT 1 OFFSET ?;
+
+-- name: SetMessageForwarded :exec
+REPLACE INTO forwarded_messages (user_number, channel_id, message_id, forwarded_at, content_hash, author)
+	VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: PruneForwardedMessages :exec
+DELETE FROM forwarded_messages WHERE user_number = ? AND forwarded_at < ?;
+
+-- name: HeldSmsMessages :many
+SELECT held_id, channel_id, message_id, content_hash, author FROM held_sms_messages
+	WHERE user_number = ?;
+
+-- name: AddHeldSmsMessage :exec
+INSERT INTO held_sms_messages (held_id, user_number, channel_id, message_id, content_hash, author)
+	VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: RemoveHeldSmsMessages :exec
+DELETE FROM held_sms_messages WHERE user_number = ? AND held_id = ?;
+
+-- name: ChannelCursor :one
+SELECT message_id FROM channel_cursors WHERE user_number = ? AND channel_id = ?;
+
+-- name: LatestChannelCursor :one
+SELECT message_id FROM channel_cursors WHERE user_number = ?
+	ORDER BY message_id DESC LIMIT 1;
+
+-- name: SetChannelCursor :exec
+REPLACE INTO channel_cursors (user_number, channel_id, message_id) VALUES (?, ?, ?);
+
+-- name: RecentSentMessage :one
+SELECT channel_id, message_id, sent_at FROM sent_messages
+	WHERE user_number = ?
+	ORDER BY message_id DESC
+	LIMIT 1 OFFSET ?;
+
+-- name: AddSentMessage :exec
+REPLACE INTO sent_messages (user_number, channel_id, message_id, sent_at) VALUES (?, ?, ?, ?);
+
+-- name: RemoveSentMessage :exec
+DELETE FROM sent_messages WHERE user_number = ? AND message_id = ?;
+
+-- name: PruneSentMessages :exec
+DELETE FROM sent_messages WHERE user_number = ? AND sent_at < ?;
+
+-- name: ChannelUsage :many
+SELECT channel_id, send_count, last_sent_at FROM channel_usage WHERE user_number = ?;
+
+-- name: AddChannelSend :exec
+INSERT INTO channel_usage (user_number, channel_id, send_count, last_sent_at) VALUES (?, ?, 1, ?)
+	ON CONFLICT (user_number, channel_id) DO UPDATE SET
+		send_count = send_count + 1,
+		last_sent_at = excluded.last_sent_at;
+
+-- name: PruneChannelUsage :exec
+DELETE FROM channel_usage WHERE user_number = ? AND last_sent_at < ?;
+
+-- name: GuildAlias :one
+SELECT alias FROM guild_aliases WHERE user_number = ? AND guild_id = ? LIMIT 1;
+
+-- name: GuildAliases :many
+SELECT guild_id, alias FROM guild_aliases WHERE user_number = ?;
+
+-- name: GuildFromAlias :one
+SELECT guild_id FROM guild_aliases WHERE user_number = ? AND alias = ? LIMIT 1;
+
+-- name: SetGuildAlias :exec
+REPLACE INTO guild_aliases (user_number, guild_id, alias) VALUES (?, ?, ?);
+
+-- name: RemoveChannelNickname :execrows
+DELETE FROM channel_nicknames WHERE user_number = ? AND nickname = ?;
+
+-- name: RenameChannelNickname :execrows
+UPDATE channel_nicknames SET nickname = sqlc.arg(new_nickname)
+	WHERE user_number = sqlc.arg(user_number) AND nickname = sqlc.arg(old_nickname);
+
+-- name: RemoveGuildAlias :execrows
+DELETE FROM guild_aliases WHERE user_number = ? AND alias = ?;
+
+-- name: RenameGuildAlias :execrows
+UPDATE guild_aliases SET alias = sqlc.arg(new_alias)
+	WHERE user_number = sqlc.arg(user_number) AND alias = sqlc.arg(old_alias);
+
+-- name: ScheduledMessages :many
+SELECT id, channel_id, user_id, content, send_at, attempts FROM scheduled_messages
+	WHERE user_number = ?
+	ORDER BY send_at ASC;
+
+-- name: AddScheduledMessage :one
+INSERT INTO scheduled_messages (user_number, channel_id, user_id, content, send_at)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id;
+
+-- name: RemoveScheduledMessage :execrows
+DELETE FROM scheduled_messages WHERE user_number = ? AND id = ?;
+
+-- name: RetryScheduledMessage :execrows
+UPDATE scheduled_messages SET send_at = ?, attempts = attempts + 1
+	WHERE user_number = ? AND id = ?;
+
+-- name: Timezone :one
+SELECT name FROM timezones WHERE user_number = ? LIMIT 1;
+
+-- name: SetTimezone :exec
+REPLACE INTO timezones (user_number, name) VALUES (?, ?);
+
+-- name: Reminders :many
+SELECT id, remind_at, note, quote, link FROM reminders
+	WHERE user_number = ?
+	ORDER BY remind_at ASC;
+
+-- name: AddReminder :one
+INSERT INTO reminders (user_number, remind_at, note, quote, link)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id;
+
+-- name: RemoveReminder :execrows
+DELETE FROM reminders WHERE user_number = ? AND id = ?;
+
+-- name: Template :one
+SELECT text FROM templates WHERE user_number = ? AND name = ?;
+
+-- name: Templates :many
+SELECT name, text FROM templates WHERE user_number = ?;
+
+-- name: SetTemplate :exec
+REPLACE INTO templates (user_number, name, text) VALUES (?, ?, ?);
+
+-- name: RemoveTemplate :execrows
+DELETE FROM templates WHERE user_number = ? AND name = ?;
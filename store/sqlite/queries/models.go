@@ -10,14 +10,158 @@ type Account struct {
 	DiscordToken string
 }
 
+type BlockRule struct {
+	ID         int64
+	UserNumber string
+	Kind       string
+	Value      string
+	GuildID    int64
+}
+
+type ChannelCursor struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+}
+
 type ChannelNickname struct {
 	UserNumber string
 	ChannelID  int64
 	Nickname   string
 }
 
+type ChannelUsage struct {
+	UserNumber string
+	ChannelID  int64
+	SendCount  int64
+	LastSentAt int64
+}
+
+type DeliverySetting struct {
+	UserNumber     string
+	Mode           string
+	DigestInterval int64
+	DigestTimes    string
+	NotifyDeletes  int64
+}
+
+type DigestEntry struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+	Author     string
+	Content    string
+}
+
+type Focu struct {
+	UserNumber string
+	ChannelID  int64
+	Until      int64
+}
+
+type ForwardedMessage struct {
+	UserNumber  string
+	ChannelID   int64
+	MessageID   int64
+	ForwardedAt int64
+	ContentHash string
+	Author      string
+}
+
+type GuildAlias struct {
+	UserNumber string
+	GuildID    int64
+	Alias      string
+}
+
+type HeldSm struct {
+	ID         int64
+	UserNumber string
+	Text       string
+}
+
+type HeldSmsMessage struct {
+	HeldID      int64
+	UserNumber  string
+	ChannelID   int64
+	MessageID   int64
+	ContentHash string
+	Author      string
+}
+
 type NumbersMuted struct {
 	UserNumber string
 	Muted      int64
 	Until      int64
 }
+
+type PendingMessage struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+}
+
+type Reminder struct {
+	ID         int64
+	UserNumber string
+	RemindAt   int64
+	Note       string
+	Quote      string
+	Link       string
+}
+
+type ScheduledMessage struct {
+	ID         int64
+	UserNumber string
+	ChannelID  int64
+	UserID     int64
+	Content    string
+	SendAt     int64
+	Attempts   int64
+}
+
+type SentMessage struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+	SentAt     int64
+}
+
+type SmsBudget struct {
+	UserNumber      string
+	DailySegments   int64
+	MonthlySegments int64
+	HourlyMessages  int64
+	OverBudget      string
+}
+
+type SmsUsage struct {
+	UserNumber string
+	Day        string
+	Segments   int64
+}
+
+type Template struct {
+	UserNumber string
+	Name       string
+	Text       string
+}
+
+type Timezone struct {
+	UserNumber string
+	Name       string
+}
+
+type VipUser struct {
+	UserNumber string
+	UserID     int64
+}
+
+type WatchRule struct {
+	ID         int64
+	UserNumber string
+	Pattern    string
+	IsRegex    int64
+	GuildID    int64
+	ChannelID  int64
+}
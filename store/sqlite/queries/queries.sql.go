@@ -52,6 +52,316 @@ func (q *Queries) Accounts(ctx context.Context) ([]Account, error) {
 	return items, nil
 }
 
+const addBlockRule = `-- name: AddBlockRule :one
+INSERT INTO block_rules (user_number, kind, value, guild_id)
+	VALUES (?, ?, ?, ?)
+	RETURNING id
+`
+
+type AddBlockRuleParams struct {
+	UserNumber string
+	Kind       string
+	Value      string
+	GuildID    int64
+}
+
+func (q *Queries) AddBlockRule(ctx context.Context, arg AddBlockRuleParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addBlockRule,
+		arg.UserNumber,
+		arg.Kind,
+		arg.Value,
+		arg.GuildID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addChannelSend = `-- name: AddChannelSend :exec
+INSERT INTO channel_usage (user_number, channel_id, send_count, last_sent_at) VALUES (?, ?, 1, ?)
+	ON CONFLICT (user_number, channel_id) DO UPDATE SET
+		send_count = send_count + 1,
+		last_sent_at = excluded.last_sent_at
+`
+
+type AddChannelSendParams struct {
+	UserNumber string
+	ChannelID  int64
+	LastSentAt int64
+}
+
+func (q *Queries) AddChannelSend(ctx context.Context, arg AddChannelSendParams) error {
+	_, err := q.db.ExecContext(ctx, addChannelSend, arg.UserNumber, arg.ChannelID, arg.LastSentAt)
+	return err
+}
+
+const addDigestEntry = `-- name: AddDigestEntry :exec
+REPLACE INTO digest_entries (user_number, channel_id, message_id, author, content) VALUES (?, ?, ?, ?, ?)
+`
+
+type AddDigestEntryParams struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+	Author     string
+	Content    string
+}
+
+func (q *Queries) AddDigestEntry(ctx context.Context, arg AddDigestEntryParams) error {
+	_, err := q.db.ExecContext(ctx, addDigestEntry,
+		arg.UserNumber,
+		arg.ChannelID,
+		arg.MessageID,
+		arg.Author,
+		arg.Content,
+	)
+	return err
+}
+
+const addHeldSms = `-- name: AddHeldSms :one
+INSERT INTO held_sms (user_number, text) VALUES (?, ?) RETURNING id
+`
+
+type AddHeldSmsParams struct {
+	UserNumber string
+	Text       string
+}
+
+func (q *Queries) AddHeldSms(ctx context.Context, arg AddHeldSmsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addHeldSms, arg.UserNumber, arg.Text)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addHeldSmsMessage = `-- name: AddHeldSmsMessage :exec
+INSERT INTO held_sms_messages (held_id, user_number, channel_id, message_id, content_hash, author)
+	VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type AddHeldSmsMessageParams struct {
+	HeldID      int64
+	UserNumber  string
+	ChannelID   int64
+	MessageID   int64
+	ContentHash string
+	Author      string
+}
+
+func (q *Queries) AddHeldSmsMessage(ctx context.Context, arg AddHeldSmsMessageParams) error {
+	_, err := q.db.ExecContext(ctx, addHeldSmsMessage,
+		arg.HeldID,
+		arg.UserNumber,
+		arg.ChannelID,
+		arg.MessageID,
+		arg.ContentHash,
+		arg.Author,
+	)
+	return err
+}
+
+const addPendingMessage = `-- name: AddPendingMessage :exec
+REPLACE INTO pending_messages (user_number, channel_id, message_id) VALUES (?, ?, ?)
+`
+
+type AddPendingMessageParams struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+}
+
+func (q *Queries) AddPendingMessage(ctx context.Context, arg AddPendingMessageParams) error {
+	_, err := q.db.ExecContext(ctx, addPendingMessage, arg.UserNumber, arg.ChannelID, arg.MessageID)
+	return err
+}
+
+const addReminder = `-- name: AddReminder :one
+INSERT INTO reminders (user_number, remind_at, note, quote, link)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id
+`
+
+type AddReminderParams struct {
+	UserNumber string
+	RemindAt   int64
+	Note       string
+	Quote      string
+	Link       string
+}
+
+func (q *Queries) AddReminder(ctx context.Context, arg AddReminderParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addReminder,
+		arg.UserNumber,
+		arg.RemindAt,
+		arg.Note,
+		arg.Quote,
+		arg.Link,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addScheduledMessage = `-- name: AddScheduledMessage :one
+INSERT INTO scheduled_messages (user_number, channel_id, user_id, content, send_at)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id
+`
+
+type AddScheduledMessageParams struct {
+	UserNumber string
+	ChannelID  int64
+	UserID     int64
+	Content    string
+	SendAt     int64
+}
+
+func (q *Queries) AddScheduledMessage(ctx context.Context, arg AddScheduledMessageParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addScheduledMessage,
+		arg.UserNumber,
+		arg.ChannelID,
+		arg.UserID,
+		arg.Content,
+		arg.SendAt,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const addSentMessage = `-- name: AddSentMessage :exec
+REPLACE INTO sent_messages (user_number, channel_id, message_id, sent_at) VALUES (?, ?, ?, ?)
+`
+
+type AddSentMessageParams struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+	SentAt     int64
+}
+
+func (q *Queries) AddSentMessage(ctx context.Context, arg AddSentMessageParams) error {
+	_, err := q.db.ExecContext(ctx, addSentMessage,
+		arg.UserNumber,
+		arg.ChannelID,
+		arg.MessageID,
+		arg.SentAt,
+	)
+	return err
+}
+
+const addSmsUsage = `-- name: AddSmsUsage :exec
+INSERT INTO sms_usage (user_number, day, segments) VALUES (?, ?, ?)
+	ON CONFLICT (user_number, day) DO UPDATE SET segments = segments + excluded.segments
+`
+
+type AddSmsUsageParams struct {
+	UserNumber string
+	Day        string
+	Segments   int64
+}
+
+func (q *Queries) AddSmsUsage(ctx context.Context, arg AddSmsUsageParams) error {
+	_, err := q.db.ExecContext(ctx, addSmsUsage, arg.UserNumber, arg.Day, arg.Segments)
+	return err
+}
+
+const addVipUser = `-- name: AddVipUser :exec
+REPLACE INTO vip_users (user_number, user_id) VALUES (?, ?)
+`
+
+type AddVipUserParams struct {
+	UserNumber string
+	UserID     int64
+}
+
+func (q *Queries) AddVipUser(ctx context.Context, arg AddVipUserParams) error {
+	_, err := q.db.ExecContext(ctx, addVipUser, arg.UserNumber, arg.UserID)
+	return err
+}
+
+const addWatchRule = `-- name: AddWatchRule :one
+INSERT INTO watch_rules (user_number, pattern, is_regex, guild_id, channel_id)
+	VALUES (?, ?, ?, ?, ?)
+	RETURNING id
+`
+
+type AddWatchRuleParams struct {
+	UserNumber string
+	Pattern    string
+	IsRegex    int64
+	GuildID    int64
+	ChannelID  int64
+}
+
+func (q *Queries) AddWatchRule(ctx context.Context, arg AddWatchRuleParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, addWatchRule,
+		arg.UserNumber,
+		arg.Pattern,
+		arg.IsRegex,
+		arg.GuildID,
+		arg.ChannelID,
+	)
+	var id int64
+	err := row.Scan(&id)
+	return id, err
+}
+
+const blockRules = `-- name: BlockRules :many
+SELECT id, kind, value, guild_id FROM block_rules WHERE user_number = ?
+`
+
+type BlockRulesRow struct {
+	ID      int64
+	Kind    string
+	Value   string
+	GuildID int64
+}
+
+func (q *Queries) BlockRules(ctx context.Context, userNumber string) ([]BlockRulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, blockRules, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BlockRulesRow
+	for rows.Next() {
+		var i BlockRulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Value,
+			&i.GuildID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const channelCursor = `-- name: ChannelCursor :one
+SELECT message_id FROM channel_cursors WHERE user_number = ? AND channel_id = ?
+`
+
+type ChannelCursorParams struct {
+	UserNumber string
+	ChannelID  int64
+}
+
+func (q *Queries) ChannelCursor(ctx context.Context, arg ChannelCursorParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, channelCursor, arg.UserNumber, arg.ChannelID)
+	var message_id int64
+	err := row.Scan(&message_id)
+	return message_id, err
+}
+
 const channelFromNickname = `-- name: ChannelFromNickname :one
 SELECT channel_id FROM channel_nicknames WHERE user_number = ? AND nickname = ? LIMIT 1
 `
@@ -116,60 +426,1204 @@ func (q *Queries) ChannelNicknames(ctx context.Context, userNumber string) ([]Ch
 	return items, nil
 }
 
-const numberIsMuted = `-- name: NumberIsMuted :one
-SELECT muted FROM numbers_muted
-	WHERE user_number = ? AND (until = 0 OR until > NOW())
-	LIMIT 1
+const channelUsage = `-- name: ChannelUsage :many
+SELECT channel_id, send_count, last_sent_at FROM channel_usage WHERE user_number = ?
 `
 
-func (q *Queries) NumberIsMuted(ctx context.Context, userNumber string) (int64, error) {
-	row := q.db.QueryRowContext(ctx, numberIsMuted, userNumber)
-	var muted int64
-	err := row.Scan(&muted)
-	return muted, err
+type ChannelUsageRow struct {
+	ChannelID  int64
+	SendCount  int64
+	LastSentAt int64
 }
 
-const setAccount = `-- name: SetAccount :exec
-REPLACE INTO accounts (user_number, server_number, discord_token) VALUES (?, ?, ?)
+func (q *Queries) ChannelUsage(ctx context.Context, userNumber string) ([]ChannelUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, channelUsage, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChannelUsageRow
+	for rows.Next() {
+		var i ChannelUsageRow
+		if err := rows.Scan(&i.ChannelID, &i.SendCount, &i.LastSentAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const clearDigestEntries = `-- name: ClearDigestEntries :exec
+DELETE FROM digest_entries WHERE user_number = ?
 `
 
-type SetAccountParams struct {
-	UserNumber   string
-	ServerNumber string
-	DiscordToken string
+func (q *Queries) ClearDigestEntries(ctx context.Context, userNumber string) error {
+	_, err := q.db.ExecContext(ctx, clearDigestEntries, userNumber)
+	return err
 }
 
-func (q *Queries) SetAccount(ctx context.Context, arg SetAccountParams) error {
-	_, err := q.db.ExecContext(ctx, setAccount, arg.UserNumber, arg.ServerNumber, arg.DiscordToken)
+const clearFocus = `-- name: ClearFocus :exec
+DELETE FROM focus WHERE user_number = ?
+`
+
+func (q *Queries) ClearFocus(ctx context.Context, userNumber string) error {
+	_, err := q.db.ExecContext(ctx, clearFocus, userNumber)
 	return err
 }
 
-const setChannelNickname = `-- name: SetChannelNickname :exec
-REPLACE INTO channel_nicknames (user_number, channel_id, nickname) VALUES (?, ?, ?)
+const deliverySettings = `-- name: DeliverySettings :one
+SELECT mode, digest_interval, digest_times, notify_deletes FROM delivery_settings WHERE user_number = ? LIMIT 1
 `
 
-type SetChannelNicknameParams struct {
+type DeliverySettingsRow struct {
+	Mode           string
+	DigestInterval int64
+	DigestTimes    string
+	NotifyDeletes  int64
+}
+
+func (q *Queries) DeliverySettings(ctx context.Context, userNumber string) (DeliverySettingsRow, error) {
+	row := q.db.QueryRowContext(ctx, deliverySettings, userNumber)
+	var i DeliverySettingsRow
+	err := row.Scan(
+		&i.Mode,
+		&i.DigestInterval,
+		&i.DigestTimes,
+		&i.NotifyDeletes,
+	)
+	return i, err
+}
+
+const digestEntries = `-- name: DigestEntries :many
+SELECT channel_id, message_id, author, content FROM digest_entries
+	WHERE user_number = ?
+	ORDER BY message_id
+`
+
+type DigestEntriesRow struct {
+	ChannelID int64
+	MessageID int64
+	Author    string
+	Content   string
+}
+
+func (q *Queries) DigestEntries(ctx context.Context, userNumber string) ([]DigestEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, digestEntries, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DigestEntriesRow
+	for rows.Next() {
+		var i DigestEntriesRow
+		if err := rows.Scan(
+			&i.ChannelID,
+			&i.MessageID,
+			&i.Author,
+			&i.Content,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const focus = `-- name: Focus :one
+SELECT channel_id, until FROM focus WHERE user_number = ? LIMIT 1
+`
+
+type FocusRow struct {
+	ChannelID int64
+	Until     int64
+}
+
+func (q *Queries) Focus(ctx context.Context, userNumber string) (FocusRow, error) {
+	row := q.db.QueryRowContext(ctx, focus, userNumber)
+	var i FocusRow
+	err := row.Scan(&i.ChannelID, &i.Until)
+	return i, err
+}
+
+const forwardedMessage = `-- name: ForwardedMessage :one
+SELECT channel_id, message_id, forwarded_at, content_hash, author FROM forwarded_messages
+	WHERE user_number = ? AND message_id = ?
+`
+
+type ForwardedMessageParams struct {
 	UserNumber string
-	ChannelID  int64
-	Nickname   string
+	MessageID  int64
 }
 
-func (q *Queries) SetChannelNickname(ctx context.Context, arg SetChannelNicknameParams) error {
-	_, err := q.db.ExecContext(ctx, setChannelNickname, arg.UserNumber, arg.ChannelID, arg.Nickname)
-	return err
+type ForwardedMessageRow struct {
+	ChannelID   int64
+	MessageID   int64
+	ForwardedAt int64
+	ContentHash string
+	Author      string
 }
 
-const setNumberMuted = `-- name: SetNumberMuted :exec
-REPLACE INTO numbers_muted (user_number, muted, until) VALUES (?, ?, ?)
+func (q *Queries) ForwardedMessage(ctx context.Context, arg ForwardedMessageParams) (ForwardedMessageRow, error) {
+	row := q.db.QueryRowContext(ctx, forwardedMessage, arg.UserNumber, arg.MessageID)
+	var i ForwardedMessageRow
+	err := row.Scan(
+		&i.ChannelID,
+		&i.MessageID,
+		&i.ForwardedAt,
+		&i.ContentHash,
+		&i.Author,
+	)
+	return i, err
+}
+
+const guildAlias = `-- name: GuildAlias :one
+SELECT alias FROM guild_aliases WHERE user_number = ? AND guild_id = ? LIMIT 1
 `
 
-type SetNumberMutedParams struct {
+type GuildAliasParams struct {
 	UserNumber string
-	Muted      int64
-	Until      int64
+	GuildID    int64
 }
 
-func (q *Queries) SetNumberMuted(ctx context.Context, arg SetNumberMutedParams) error {
-	_, err := q.db.ExecContext(ctx, setNumberMuted, arg.UserNumber, arg.Muted, arg.Until)
-	return err
+func (q *Queries) GuildAlias(ctx context.Context, arg GuildAliasParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, guildAlias, arg.UserNumber, arg.GuildID)
+	var alias string
+	err := row.Scan(&alias)
+	return alias, err
+}
+
+const guildAliases = `-- name: GuildAliases :many
+SELECT guild_id, alias FROM guild_aliases WHERE user_number = ?
+`
+
+type GuildAliasesRow struct {
+	GuildID int64
+	Alias   string
+}
+
+func (q *Queries) GuildAliases(ctx context.Context, userNumber string) ([]GuildAliasesRow, error) {
+	rows, err := q.db.QueryContext(ctx, guildAliases, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GuildAliasesRow
+	for rows.Next() {
+		var i GuildAliasesRow
+		if err := rows.Scan(&i.GuildID, &i.Alias); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const guildFromAlias = `-- name: GuildFromAlias :one
+SELECT guild_id FROM guild_aliases WHERE user_number = ? AND alias = ? LIMIT 1
+`
+
+type GuildFromAliasParams struct {
+	UserNumber string
+	Alias      string
+}
+
+func (q *Queries) GuildFromAlias(ctx context.Context, arg GuildFromAliasParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, guildFromAlias, arg.UserNumber, arg.Alias)
+	var guild_id int64
+	err := row.Scan(&guild_id)
+	return guild_id, err
+}
+
+const heldSms = `-- name: HeldSms :many
+SELECT id, text FROM held_sms WHERE user_number = ? ORDER BY id ASC
+`
+
+type HeldSmsRow struct {
+	ID   int64
+	Text string
+}
+
+func (q *Queries) HeldSms(ctx context.Context, userNumber string) ([]HeldSmsRow, error) {
+	rows, err := q.db.QueryContext(ctx, heldSms, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HeldSmsRow
+	for rows.Next() {
+		var i HeldSmsRow
+		if err := rows.Scan(&i.ID, &i.Text); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const heldSmsMessages = `-- name: HeldSmsMessages :many
+SELECT held_id, channel_id, message_id, content_hash, author FROM held_sms_messages
+	WHERE user_number = ?
+`
+
+type HeldSmsMessagesRow struct {
+	HeldID      int64
+	ChannelID   int64
+	MessageID   int64
+	ContentHash string
+	Author      string
+}
+
+func (q *Queries) HeldSmsMessages(ctx context.Context, userNumber string) ([]HeldSmsMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, heldSmsMessages, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []HeldSmsMessagesRow
+	for rows.Next() {
+		var i HeldSmsMessagesRow
+		if err := rows.Scan(
+			&i.HeldID,
+			&i.ChannelID,
+			&i.MessageID,
+			&i.ContentHash,
+			&i.Author,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const latestChannelCursor = `-- name: LatestChannelCursor :one
+SELECT message_id FROM channel_cursors WHERE user_number = ?
+	ORDER BY message_id DESC LIMIT 1
+`
+
+func (q *Queries) LatestChannelCursor(ctx context.Context, userNumber string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, latestChannelCursor, userNumber)
+	var message_id int64
+	err := row.Scan(&message_id)
+	return message_id, err
+}
+
+const numberIsMuted = `-- name: NumberIsMuted :one
+SELECT muted FROM numbers_muted
+	WHERE user_number = ? AND (until = 0 OR until > NOW())
+	LIMIT 1
+`
+
+func (q *Queries) NumberIsMuted(ctx context.Context, userNumber string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, numberIsMuted, userNumber)
+	var muted int64
+	err := row.Scan(&muted)
+	return muted, err
+}
+
+const pendingMessages = `-- name: PendingMessages :many
+SELECT channel_id, message_id FROM pending_messages WHERE user_number = ? ORDER BY message_id
+`
+
+type PendingMessagesRow struct {
+	ChannelID int64
+	MessageID int64
+}
+
+func (q *Queries) PendingMessages(ctx context.Context, userNumber string) ([]PendingMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, pendingMessages, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PendingMessagesRow
+	for rows.Next() {
+		var i PendingMessagesRow
+		if err := rows.Scan(&i.ChannelID, &i.MessageID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const pruneChannelUsage = `-- name: PruneChannelUsage :exec
+DELETE FROM channel_usage WHERE user_number = ? AND last_sent_at < ?
+`
+
+type PruneChannelUsageParams struct {
+	UserNumber string
+	LastSentAt int64
+}
+
+func (q *Queries) PruneChannelUsage(ctx context.Context, arg PruneChannelUsageParams) error {
+	_, err := q.db.ExecContext(ctx, pruneChannelUsage, arg.UserNumber, arg.LastSentAt)
+	return err
+}
+
+const pruneForwardedMessages = `-- name: PruneForwardedMessages :exec
+DELETE FROM forwarded_messages WHERE user_number = ? AND forwarded_at < ?
+`
+
+type PruneForwardedMessagesParams struct {
+	UserNumber  string
+	ForwardedAt int64
+}
+
+func (q *Queries) PruneForwardedMessages(ctx context.Context, arg PruneForwardedMessagesParams) error {
+	_, err := q.db.ExecContext(ctx, pruneForwardedMessages, arg.UserNumber, arg.ForwardedAt)
+	return err
+}
+
+const pruneSentMessages = `-- name: PruneSentMessages :exec
+DELETE FROM sent_messages WHERE user_number = ? AND sent_at < ?
+`
+
+type PruneSentMessagesParams struct {
+	UserNumber string
+	SentAt     int64
+}
+
+func (q *Queries) PruneSentMessages(ctx context.Context, arg PruneSentMessagesParams) error {
+	_, err := q.db.ExecContext(ctx, pruneSentMessages, arg.UserNumber, arg.SentAt)
+	return err
+}
+
+const pruneSmsUsage = `-- name: PruneSmsUsage :exec
+DELETE FROM sms_usage WHERE user_number = ? AND day < ?
+`
+
+type PruneSmsUsageParams struct {
+	UserNumber string
+	Day        string
+}
+
+func (q *Queries) PruneSmsUsage(ctx context.Context, arg PruneSmsUsageParams) error {
+	_, err := q.db.ExecContext(ctx, pruneSmsUsage, arg.UserNumber, arg.Day)
+	return err
+}
+
+const recentForwardedMessage = `-- name: RecentForwardedMessage :one
+SELECT channel_id, message_id, forwarded_at, content_hash, author FROM forwarded_messages
+	WHERE user_number = ?
+	ORDER BY forwarded_at DESC, message_id DESC
+	LIMIT 1 OFFSET ?
+`
+
+type RecentForwardedMessageParams struct {
+	UserNumber string
+	Offset     int64
+}
+
+type RecentForwardedMessageRow struct {
+	ChannelID   int64
+	MessageID   int64
+	ForwardedAt int64
+	ContentHash string
+	Author      string
+}
+
+func (q *Queries) RecentForwardedMessage(ctx context.Context, arg RecentForwardedMessageParams) (RecentForwardedMessageRow, error) {
+	row := q.db.QueryRowContext(ctx, recentForwardedMessage, arg.UserNumber, arg.Offset)
+	var i RecentForwardedMessageRow
+	err := row.Scan(
+		&i.ChannelID,
+		&i.MessageID,
+		&i.ForwardedAt,
+		&i.ContentHash,
+		&i.Author,
+	)
+	return i, err
+}
+
+const recentSentMessage = `-- name: RecentSentMessage :one
+SELECT channel_id, message_id, sent_at FROM sent_messages
+	WHERE user_number = ?
+	ORDER BY message_id DESC
+	LIMIT 1 OFFSET ?
+`
+
+type RecentSentMessageParams struct {
+	UserNumber string
+	Offset     int64
+}
+
+type RecentSentMessageRow struct {
+	ChannelID int64
+	MessageID int64
+	SentAt    int64
+}
+
+func (q *Queries) RecentSentMessage(ctx context.Context, arg RecentSentMessageParams) (RecentSentMessageRow, error) {
+	row := q.db.QueryRowContext(ctx, recentSentMessage, arg.UserNumber, arg.Offset)
+	var i RecentSentMessageRow
+	err := row.Scan(&i.ChannelID, &i.MessageID, &i.SentAt)
+	return i, err
+}
+
+const reminders = `-- name: Reminders :many
+SELECT id, remind_at, note, quote, link FROM reminders
+	WHERE user_number = ?
+	ORDER BY remind_at ASC
+`
+
+type RemindersRow struct {
+	ID       int64
+	RemindAt int64
+	Note     string
+	Quote    string
+	Link     string
+}
+
+func (q *Queries) Reminders(ctx context.Context, userNumber string) ([]RemindersRow, error) {
+	rows, err := q.db.QueryContext(ctx, reminders, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RemindersRow
+	for rows.Next() {
+		var i RemindersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RemindAt,
+			&i.Note,
+			&i.Quote,
+			&i.Link,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeBlockRule = `-- name: RemoveBlockRule :execrows
+DELETE FROM block_rules WHERE user_number = ? AND id = ?
+`
+
+type RemoveBlockRuleParams struct {
+	UserNumber string
+	ID         int64
+}
+
+func (q *Queries) RemoveBlockRule(ctx context.Context, arg RemoveBlockRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeBlockRule, arg.UserNumber, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeChannelNickname = `-- name: RemoveChannelNickname :execrows
+DELETE FROM channel_nicknames WHERE user_number = ? AND nickname = ?
+`
+
+type RemoveChannelNicknameParams struct {
+	UserNumber string
+	Nickname   string
+}
+
+func (q *Queries) RemoveChannelNickname(ctx context.Context, arg RemoveChannelNicknameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeChannelNickname, arg.UserNumber, arg.Nickname)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeDigestEntry = `-- name: RemoveDigestEntry :exec
+DELETE FROM digest_entries WHERE user_number = ? AND message_id = ?
+`
+
+type RemoveDigestEntryParams struct {
+	UserNumber string
+	MessageID  int64
+}
+
+func (q *Queries) RemoveDigestEntry(ctx context.Context, arg RemoveDigestEntryParams) error {
+	_, err := q.db.ExecContext(ctx, removeDigestEntry, arg.UserNumber, arg.MessageID)
+	return err
+}
+
+const removeGuildAlias = `-- name: RemoveGuildAlias :execrows
+DELETE FROM guild_aliases WHERE user_number = ? AND alias = ?
+`
+
+type RemoveGuildAliasParams struct {
+	UserNumber string
+	Alias      string
+}
+
+func (q *Queries) RemoveGuildAlias(ctx context.Context, arg RemoveGuildAliasParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeGuildAlias, arg.UserNumber, arg.Alias)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeHeldSms = `-- name: RemoveHeldSms :execrows
+DELETE FROM held_sms WHERE user_number = ? AND id = ?
+`
+
+type RemoveHeldSmsParams struct {
+	UserNumber string
+	ID         int64
+}
+
+func (q *Queries) RemoveHeldSms(ctx context.Context, arg RemoveHeldSmsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeHeldSms, arg.UserNumber, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeHeldSmsMessages = `-- name: RemoveHeldSmsMessages :exec
+DELETE FROM held_sms_messages WHERE user_number = ? AND held_id = ?
+`
+
+type RemoveHeldSmsMessagesParams struct {
+	UserNumber string
+	HeldID     int64
+}
+
+func (q *Queries) RemoveHeldSmsMessages(ctx context.Context, arg RemoveHeldSmsMessagesParams) error {
+	_, err := q.db.ExecContext(ctx, removeHeldSmsMessages, arg.UserNumber, arg.HeldID)
+	return err
+}
+
+const removePendingMessage = `-- name: RemovePendingMessage :exec
+DELETE FROM pending_messages WHERE user_number = ? AND message_id = ?
+`
+
+type RemovePendingMessageParams struct {
+	UserNumber string
+	MessageID  int64
+}
+
+func (q *Queries) RemovePendingMessage(ctx context.Context, arg RemovePendingMessageParams) error {
+	_, err := q.db.ExecContext(ctx, removePendingMessage, arg.UserNumber, arg.MessageID)
+	return err
+}
+
+const removeReminder = `-- name: RemoveReminder :execrows
+DELETE FROM reminders WHERE user_number = ? AND id = ?
+`
+
+type RemoveReminderParams struct {
+	UserNumber string
+	ID         int64
+}
+
+func (q *Queries) RemoveReminder(ctx context.Context, arg RemoveReminderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeReminder, arg.UserNumber, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeScheduledMessage = `-- name: RemoveScheduledMessage :execrows
+DELETE FROM scheduled_messages WHERE user_number = ? AND id = ?
+`
+
+type RemoveScheduledMessageParams struct {
+	UserNumber string
+	ID         int64
+}
+
+func (q *Queries) RemoveScheduledMessage(ctx context.Context, arg RemoveScheduledMessageParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeScheduledMessage, arg.UserNumber, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeSentMessage = `-- name: RemoveSentMessage :exec
+DELETE FROM sent_messages WHERE user_number = ? AND message_id = ?
+`
+
+type RemoveSentMessageParams struct {
+	UserNumber string
+	MessageID  int64
+}
+
+func (q *Queries) RemoveSentMessage(ctx context.Context, arg RemoveSentMessageParams) error {
+	_, err := q.db.ExecContext(ctx, removeSentMessage, arg.UserNumber, arg.MessageID)
+	return err
+}
+
+const removeTemplate = `-- name: RemoveTemplate :execrows
+DELETE FROM templates WHERE user_number = ? AND name = ?
+`
+
+type RemoveTemplateParams struct {
+	UserNumber string
+	Name       string
+}
+
+func (q *Queries) RemoveTemplate(ctx context.Context, arg RemoveTemplateParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeTemplate, arg.UserNumber, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeVipUser = `-- name: RemoveVipUser :execrows
+DELETE FROM vip_users WHERE user_number = ? AND user_id = ?
+`
+
+type RemoveVipUserParams struct {
+	UserNumber string
+	UserID     int64
+}
+
+func (q *Queries) RemoveVipUser(ctx context.Context, arg RemoveVipUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeVipUser, arg.UserNumber, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeWatchRule = `-- name: RemoveWatchRule :execrows
+DELETE FROM watch_rules WHERE user_number = ? AND id = ?
+`
+
+type RemoveWatchRuleParams struct {
+	UserNumber string
+	ID         int64
+}
+
+func (q *Queries) RemoveWatchRule(ctx context.Context, arg RemoveWatchRuleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeWatchRule, arg.UserNumber, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameChannelNickname = `-- name: RenameChannelNickname :execrows
+UPDATE channel_nicknames SET nickname = ?1
+	WHERE user_number = ?2 AND nickname = ?3
+`
+
+type RenameChannelNicknameParams struct {
+	NewNickname string
+	UserNumber  string
+	OldNickname string
+}
+
+func (q *Queries) RenameChannelNickname(ctx context.Context, arg RenameChannelNicknameParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, renameChannelNickname, arg.NewNickname, arg.UserNumber, arg.OldNickname)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameGuildAlias = `-- name: RenameGuildAlias :execrows
+UPDATE guild_aliases SET alias = ?1
+	WHERE user_number = ?2 AND alias = ?3
+`
+
+type RenameGuildAliasParams struct {
+	NewAlias   string
+	UserNumber string
+	OldAlias   string
+}
+
+func (q *Queries) RenameGuildAlias(ctx context.Context, arg RenameGuildAliasParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, renameGuildAlias, arg.NewAlias, arg.UserNumber, arg.OldAlias)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const retryScheduledMessage = `-- name: RetryScheduledMessage :execrows
+UPDATE scheduled_messages SET send_at = ?, attempts = attempts + 1
+	WHERE user_number = ? AND id = ?
+`
+
+type RetryScheduledMessageParams struct {
+	SendAt     int64
+	UserNumber string
+	ID         int64
+}
+
+func (q *Queries) RetryScheduledMessage(ctx context.Context, arg RetryScheduledMessageParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, retryScheduledMessage, arg.SendAt, arg.UserNumber, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const scheduledMessages = `-- name: ScheduledMessages :many
+SELECT id, channel_id, user_id, content, send_at, attempts FROM scheduled_messages
+	WHERE user_number = ?
+	ORDER BY send_at ASC
+`
+
+type ScheduledMessagesRow struct {
+	ID        int64
+	ChannelID int64
+	UserID    int64
+	Content   string
+	SendAt    int64
+	Attempts  int64
+}
+
+func (q *Queries) ScheduledMessages(ctx context.Context, userNumber string) ([]ScheduledMessagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, scheduledMessages, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScheduledMessagesRow
+	for rows.Next() {
+		var i ScheduledMessagesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ChannelID,
+			&i.UserID,
+			&i.Content,
+			&i.SendAt,
+			&i.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setAccount = `-- name: SetAccount :exec
+REPLACE INTO accounts (user_number, server_number, discord_token) VALUES (?, ?, ?)
+`
+
+type SetAccountParams struct {
+	UserNumber   string
+	ServerNumber string
+	DiscordToken string
+}
+
+func (q *Queries) SetAccount(ctx context.Context, arg SetAccountParams) error {
+	_, err := q.db.ExecContext(ctx, setAccount, arg.UserNumber, arg.ServerNumber, arg.DiscordToken)
+	return err
+}
+
+const setChannelCursor = `-- name: SetChannelCursor :exec
+REPLACE INTO channel_cursors (user_number, channel_id, message_id) VALUES (?, ?, ?)
+`
+
+type SetChannelCursorParams struct {
+	UserNumber string
+	ChannelID  int64
+	MessageID  int64
+}
+
+func (q *Queries) SetChannelCursor(ctx context.Context, arg SetChannelCursorParams) error {
+	_, err := q.db.ExecContext(ctx, setChannelCursor, arg.UserNumber, arg.ChannelID, arg.MessageID)
+	return err
+}
+
+const setChannelNickname = `-- name: SetChannelNickname :exec
+REPLACE INTO channel_nicknames (user_number, channel_id, nickname) VALUES (?, ?, ?)
+`
+
+type SetChannelNicknameParams struct {
+	UserNumber string
+	ChannelID  int64
+	Nickname   string
+}
+
+func (q *Queries) SetChannelNickname(ctx context.Context, arg SetChannelNicknameParams) error {
+	_, err := q.db.ExecContext(ctx, setChannelNickname, arg.UserNumber, arg.ChannelID, arg.Nickname)
+	return err
+}
+
+const setDeliverySettings = `-- name: SetDeliverySettings :exec
+REPLACE INTO delivery_settings (user_number, mode, digest_interval, digest_times, notify_deletes)
+	VALUES (?, ?, ?, ?, ?)
+`
+
+type SetDeliverySettingsParams struct {
+	UserNumber     string
+	Mode           string
+	DigestInterval int64
+	DigestTimes    string
+	NotifyDeletes  int64
+}
+
+func (q *Queries) SetDeliverySettings(ctx context.Context, arg SetDeliverySettingsParams) error {
+	_, err := q.db.ExecContext(ctx, setDeliverySettings,
+		arg.UserNumber,
+		arg.Mode,
+		arg.DigestInterval,
+		arg.DigestTimes,
+		arg.NotifyDeletes,
+	)
+	return err
+}
+
+const setFocus = `-- name: SetFocus :exec
+REPLACE INTO focus (user_number, channel_id, until) VALUES (?, ?, ?)
+`
+
+type SetFocusParams struct {
+	UserNumber string
+	ChannelID  int64
+	Until      int64
+}
+
+func (q *Queries) SetFocus(ctx context.Context, arg SetFocusParams) error {
+	_, err := q.db.ExecContext(ctx, setFocus, arg.UserNumber, arg.ChannelID, arg.Until)
+	return err
+}
+
+const setGuildAlias = `-- name: SetGuildAlias :exec
+REPLACE INTO guild_aliases (user_number, guild_id, alias) VALUES (?, ?, ?)
+`
+
+type SetGuildAliasParams struct {
+	UserNumber string
+	GuildID    int64
+	Alias      string
+}
+
+func (q *Queries) SetGuildAlias(ctx context.Context, arg SetGuildAliasParams) error {
+	_, err := q.db.ExecContext(ctx, setGuildAlias, arg.UserNumber, arg.GuildID, arg.Alias)
+	return err
+}
+
+const setMessageForwarded = `-- name: SetMessageForwarded :exec
+REPLACE INTO forwarded_messages (user_number, channel_id, message_id, forwarded_at, content_hash, author)
+	VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type SetMessageForwardedParams struct {
+	UserNumber  string
+	ChannelID   int64
+	MessageID   int64
+	ForwardedAt int64
+	ContentHash string
+	Author      string
+}
+
+func (q *Queries) SetMessageForwarded(ctx context.Context, arg SetMessageForwardedParams) error {
+	_, err := q.db.ExecContext(ctx, setMessageForwarded,
+		arg.UserNumber,
+		arg.ChannelID,
+		arg.MessageID,
+		arg.ForwardedAt,
+		arg.ContentHash,
+		arg.Author,
+	)
+	return err
+}
+
+const setNumberMuted = `-- name: SetNumberMuted :exec
+REPLACE INTO numbers_muted (user_number, muted, until) VALUES (?, ?, ?)
+`
+
+type SetNumberMutedParams struct {
+	UserNumber string
+	Muted      int64
+	Until      int64
+}
+
+func (q *Queries) SetNumberMuted(ctx context.Context, arg SetNumberMutedParams) error {
+	_, err := q.db.ExecContext(ctx, setNumberMuted, arg.UserNumber, arg.Muted, arg.Until)
+	return err
+}
+
+const setSmsBudget = `-- name: SetSmsBudget :exec
+REPLACE INTO sms_budgets (user_number, daily_segments, monthly_segments, hourly_messages, over_budget)
+	VALUES (?, ?, ?, ?, ?)
+`
+
+type SetSmsBudgetParams struct {
+	UserNumber      string
+	DailySegments   int64
+	MonthlySegments int64
+	HourlyMessages  int64
+	OverBudget      string
+}
+
+func (q *Queries) SetSmsBudget(ctx context.Context, arg SetSmsBudgetParams) error {
+	_, err := q.db.ExecContext(ctx, setSmsBudget,
+		arg.UserNumber,
+		arg.DailySegments,
+		arg.MonthlySegments,
+		arg.HourlyMessages,
+		arg.OverBudget,
+	)
+	return err
+}
+
+const setTemplate = `-- name: SetTemplate :exec
+REPLACE INTO templates (user_number, name, text) VALUES (?, ?, ?)
+`
+
+type SetTemplateParams struct {
+	UserNumber string
+	Name       string
+	Text       string
+}
+
+func (q *Queries) SetTemplate(ctx context.Context, arg SetTemplateParams) error {
+	_, err := q.db.ExecContext(ctx, setTemplate, arg.UserNumber, arg.Name, arg.Text)
+	return err
+}
+
+const setTimezone = `-- name: SetTimezone :exec
+REPLACE INTO timezones (user_number, name) VALUES (?, ?)
+`
+
+type SetTimezoneParams struct {
+	UserNumber string
+	Name       string
+}
+
+func (q *Queries) SetTimezone(ctx context.Context, arg SetTimezoneParams) error {
+	_, err := q.db.ExecContext(ctx, setTimezone, arg.UserNumber, arg.Name)
+	return err
+}
+
+const smsBudget = `-- name: SmsBudget :one
+SELECT daily_segments, monthly_segments, hourly_messages, over_budget FROM sms_budgets
+	WHERE user_number = ?
+	LIMIT 1
+`
+
+type SmsBudgetRow struct {
+	DailySegments   int64
+	MonthlySegments int64
+	HourlyMessages  int64
+	OverBudget      string
+}
+
+func (q *Queries) SmsBudget(ctx context.Context, userNumber string) (SmsBudgetRow, error) {
+	row := q.db.QueryRowContext(ctx, smsBudget, userNumber)
+	var i SmsBudgetRow
+	err := row.Scan(
+		&i.DailySegments,
+		&i.MonthlySegments,
+		&i.HourlyMessages,
+		&i.OverBudget,
+	)
+	return i, err
+}
+
+const smsUsage = `-- name: SmsUsage :many
+SELECT segments FROM sms_usage WHERE user_number = ? AND day >= ?
+`
+
+type SmsUsageParams struct {
+	UserNumber string
+	Day        string
+}
+
+func (q *Queries) SmsUsage(ctx context.Context, arg SmsUsageParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, smsUsage, arg.UserNumber, arg.Day)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var segments int64
+		if err := rows.Scan(&segments); err != nil {
+			return nil, err
+		}
+		items = append(items, segments)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const template = `-- name: Template :one
+SELECT text FROM templates WHERE user_number = ? AND name = ?
+`
+
+type TemplateParams struct {
+	UserNumber string
+	Name       string
+}
+
+func (q *Queries) Template(ctx context.Context, arg TemplateParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, template, arg.UserNumber, arg.Name)
+	var text string
+	err := row.Scan(&text)
+	return text, err
+}
+
+const templates = `-- name: Templates :many
+SELECT name, text FROM templates WHERE user_number = ?
+`
+
+type TemplatesRow struct {
+	Name string
+	Text string
+}
+
+func (q *Queries) Templates(ctx context.Context, userNumber string) ([]TemplatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, templates, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplatesRow
+	for rows.Next() {
+		var i TemplatesRow
+		if err := rows.Scan(&i.Name, &i.Text); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const timezone = `-- name: Timezone :one
+SELECT name FROM timezones WHERE user_number = ? LIMIT 1
+`
+
+func (q *Queries) Timezone(ctx context.Context, userNumber string) (string, error) {
+	row := q.db.QueryRowContext(ctx, timezone, userNumber)
+	var name string
+	err := row.Scan(&name)
+	return name, err
+}
+
+const vipUsers = `-- name: VipUsers :many
+SELECT user_id FROM vip_users WHERE user_number = ?
+`
+
+func (q *Queries) VipUsers(ctx context.Context, userNumber string) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, vipUsers, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var user_id int64
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const watchRules = `-- name: WatchRules :many
+SELECT id, pattern, is_regex, guild_id, channel_id FROM watch_rules WHERE user_number = ?
+`
+
+type WatchRulesRow struct {
+	ID        int64
+	Pattern   string
+	IsRegex   int64
+	GuildID   int64
+	ChannelID int64
+}
+
+func (q *Queries) WatchRules(ctx context.Context, userNumber string) ([]WatchRulesRow, error) {
+	rows, err := q.db.QueryContext(ctx, watchRules, userNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WatchRulesRow
+	for rows.Next() {
+		var i WatchRulesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Pattern,
+			&i.IsRegex,
+			&i.GuildID,
+			&i.ChannelID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
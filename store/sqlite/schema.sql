@@ -16,3 +16,194 @@ CREATE TABLE channel_nicknames (
 	nickname TEXT NOT NULL,
 	UNIQUE(user_number, channel_id)
 );
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE watch_rules (
+	id INTEGER PRIMARY KEY,
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	pattern TEXT NOT NULL,
+	is_regex INT NOT NULL DEFAULT 0,
+	guild_id BIGINT NOT NULL DEFAULT 0,
+	channel_id BIGINT NOT NULL DEFAULT 0
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE vip_users (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	user_id BIGINT NOT NULL,
+	UNIQUE(user_number, user_id)
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE block_rules (
+	id INTEGER PRIMARY KEY,
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	kind TEXT NOT NULL,
+	value TEXT NOT NULL,
+	guild_id BIGINT NOT NULL DEFAULT 0
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE sms_budgets (
+	user_number TEXT PRIMARY KEY REFERENCES accounts(user_number),
+	daily_segments INT NOT NULL DEFAULT 0,
+	monthly_segments INT NOT NULL DEFAULT 0,
+	hourly_messages INT NOT NULL DEFAULT 0,
+	over_budget TEXT NOT NULL DEFAULT 'hold'
+);
+
+CREATE TABLE sms_usage (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	day TEXT NOT NULL,
+	segments INT NOT NULL DEFAULT 0,
+	UNIQUE(user_number, day)
+);
+
+CREATE TABLE held_sms (
+	id INTEGER PRIMARY KEY,
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	text TEXT NOT NULL
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE delivery_settings (
+	user_number TEXT PRIMARY KEY REFERENCES accounts(user_number),
+	mode TEXT NOT NULL DEFAULT 'realtime',
+	digest_interval INT NOT NULL DEFAULT 0,
+	digest_times TEXT NOT NULL DEFAULT ''
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE pending_messages (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	message_id BIGINT NOT NULL,
+	UNIQUE(user_number, message_id)
+);
+
+CREATE TABLE digest_entries (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	message_id BIGINT NOT NULL,
+	author TEXT NOT NULL,
+	content TEXT NOT NULL,
+	UNIQUE(user_number, message_id)
+);
+
+CREATE TABLE forwarded_messages (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	message_id BIGINT NOT NULL,
+	forwarded_at INT NOT NULL,
+	UNIQUE(user_number, message_id)
+);
+
+CREATE TABLE held_sms_messages (
+	held_id INTEGER NOT NULL REFERENCES held_sms(id),
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	message_id BIGINT NOT NULL
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE channel_cursors (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	message_id BIGINT NOT NULL,
+	UNIQUE(user_number, channel_id)
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+ALTER TABLE forwarded_messages ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE held_sms_messages ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+
+--------------------------------- NEW VERSION ---------------------------------
+
+ALTER TABLE forwarded_messages ADD COLUMN author TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE delivery_settings ADD COLUMN notify_deletes INT NOT NULL DEFAULT 0;
+
+ALTER TABLE held_sms_messages ADD COLUMN author TEXT NOT NULL DEFAULT '';
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE sent_messages (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	message_id BIGINT NOT NULL,
+	sent_at INT NOT NULL,
+	UNIQUE(user_number, message_id)
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE channel_usage (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	send_count INT NOT NULL DEFAULT 0,
+	last_sent_at INT NOT NULL DEFAULT 0,
+	UNIQUE(user_number, channel_id)
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE guild_aliases (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	guild_id BIGINT NOT NULL,
+	alias TEXT NOT NULL,
+	UNIQUE(user_number, guild_id)
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE focus (
+	user_number TEXT PRIMARY KEY REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	until INT NOT NULL
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE scheduled_messages (
+	id INTEGER PRIMARY KEY,
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	channel_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL DEFAULT 0,
+	content TEXT NOT NULL,
+	send_at INT NOT NULL,
+	attempts INT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE timezones (
+	user_number TEXT PRIMARY KEY REFERENCES accounts(user_number),
+	name TEXT NOT NULL
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE reminders (
+	id INTEGER PRIMARY KEY,
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	remind_at INT NOT NULL,
+	note TEXT NOT NULL DEFAULT '',
+	quote TEXT NOT NULL DEFAULT '',
+	link TEXT NOT NULL DEFAULT ''
+);
+
+--------------------------------- NEW VERSION ---------------------------------
+
+CREATE TABLE templates (
+	user_number TEXT NOT NULL REFERENCES accounts(user_number),
+	name TEXT NOT NULL,
+	text TEXT NOT NULL,
+	UNIQUE(user_number, name)
+);
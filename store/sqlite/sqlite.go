@@ -3,6 +3,7 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	_ "embed"
@@ -64,7 +65,8 @@ func (s *SQLite) Account(ctx context.Context, userNumber string) (store.AccountS
 	}
 
 	return &accountStore{
-		q: s.q,
+		q:  s.q,
+		db: s.db,
 		account: store.Account{
 			UserNumber:   userNumber,
 			ServerNumber: v.ServerNumber,
@@ -102,11 +104,28 @@ func (s *SQLite) SetAccount(ctx context.Context, info store.Account) error {
 
 type accountStore struct {
 	q       *queries.Queries
+	db      *sql.DB
 	account store.Account
 }
 
 var _ store.AccountStore = (*accountStore)(nil)
 
+// inTx runs f in a transaction, which is committed if f returns nil and rolled
+// back otherwise.
+func (s *accountStore) inTx(ctx context.Context, f func(q *queries.Queries) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if err := f(s.q.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return errors.Wrap(tx.Commit(), "failed to commit transaction")
+}
+
 func (s *accountStore) Account() store.Account {
 	return s.account
 }
@@ -181,6 +200,849 @@ func (s *accountStore) SetChannelNickname(ctx context.Context, chID discord.Chan
 	return sqliteErr(err)
 }
 
+func (s *accountStore) RemoveChannelNickname(ctx context.Context, nickname string) error {
+	n, err := s.q.RemoveChannelNickname(ctx, queries.RemoveChannelNicknameParams{
+		UserNumber: s.account.UserNumber,
+		Nickname:   nickname,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) RenameChannelNickname(ctx context.Context, old, new string) error {
+	n, err := s.q.RenameChannelNickname(ctx, queries.RenameChannelNicknameParams{
+		NewNickname: new,
+		UserNumber:  s.account.UserNumber,
+		OldNickname: old,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) GuildAlias(ctx context.Context, guildID discord.GuildID) (string, error) {
+	alias, err := s.q.GuildAlias(ctx, queries.GuildAliasParams{
+		UserNumber: s.account.UserNumber,
+		GuildID:    int64(guildID),
+	})
+	if err != nil {
+		return "", sqliteErr(err)
+	}
+	return alias, nil
+}
+
+func (s *accountStore) GuildAliases(ctx context.Context) (map[discord.GuildID]string, error) {
+	rows, err := s.q.GuildAliases(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	aliases := make(map[discord.GuildID]string, len(rows))
+	for _, v := range rows {
+		aliases[discord.GuildID(v.GuildID)] = v.Alias
+	}
+	return aliases, nil
+}
+
+func (s *accountStore) GuildFromAlias(ctx context.Context, alias string) (discord.GuildID, error) {
+	id, err := s.q.GuildFromAlias(ctx, queries.GuildFromAliasParams{
+		UserNumber: s.account.UserNumber,
+		Alias:      alias,
+	})
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	return discord.GuildID(id), nil
+}
+
+func (s *accountStore) SetGuildAlias(ctx context.Context, guildID discord.GuildID, alias string) error {
+	err := s.q.SetGuildAlias(ctx, queries.SetGuildAliasParams{
+		UserNumber: s.account.UserNumber,
+		GuildID:    int64(guildID),
+		Alias:      alias,
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) RemoveGuildAlias(ctx context.Context, alias string) error {
+	n, err := s.q.RemoveGuildAlias(ctx, queries.RemoveGuildAliasParams{
+		UserNumber: s.account.UserNumber,
+		Alias:      alias,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) RenameGuildAlias(ctx context.Context, old, new string) error {
+	n, err := s.q.RenameGuildAlias(ctx, queries.RenameGuildAliasParams{
+		NewAlias:   new,
+		UserNumber: s.account.UserNumber,
+		OldAlias:   old,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) WatchRules(ctx context.Context) ([]store.WatchRule, error) {
+	rows, err := s.q.WatchRules(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	rules := make([]store.WatchRule, len(rows))
+	for i, v := range rows {
+		rules[i] = store.WatchRule{
+			ID:        v.ID,
+			Pattern:   v.Pattern,
+			IsRegex:   v.IsRegex != 0,
+			GuildID:   discord.GuildID(v.GuildID),
+			ChannelID: discord.ChannelID(v.ChannelID),
+		}
+	}
+	return rules, nil
+}
+
+func (s *accountStore) AddWatchRule(ctx context.Context, rule store.WatchRule) (int64, error) {
+	id, err := s.q.AddWatchRule(ctx, queries.AddWatchRuleParams{
+		UserNumber: s.account.UserNumber,
+		Pattern:    rule.Pattern,
+		IsRegex:    boolToInt(rule.IsRegex),
+		GuildID:    int64(rule.GuildID),
+		ChannelID:  int64(rule.ChannelID),
+	})
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	return id, nil
+}
+
+func (s *accountStore) RemoveWatchRule(ctx context.Context, id int64) error {
+	n, err := s.q.RemoveWatchRule(ctx, queries.RemoveWatchRuleParams{
+		UserNumber: s.account.UserNumber,
+		ID:         id,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) VIPUsers(ctx context.Context) ([]discord.UserID, error) {
+	rows, err := s.q.VipUsers(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	userIDs := make([]discord.UserID, len(rows))
+	for i, v := range rows {
+		userIDs[i] = discord.UserID(v)
+	}
+	return userIDs, nil
+}
+
+func (s *accountStore) AddVIPUser(ctx context.Context, userID discord.UserID) error {
+	err := s.q.AddVipUser(ctx, queries.AddVipUserParams{
+		UserNumber: s.account.UserNumber,
+		UserID:     int64(userID),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) RemoveVIPUser(ctx context.Context, userID discord.UserID) error {
+	n, err := s.q.RemoveVipUser(ctx, queries.RemoveVipUserParams{
+		UserNumber: s.account.UserNumber,
+		UserID:     int64(userID),
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) BlockRules(ctx context.Context) ([]store.BlockRule, error) {
+	rows, err := s.q.BlockRules(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	rules := make([]store.BlockRule, len(rows))
+	for i, v := range rows {
+		rules[i] = store.BlockRule{
+			ID:      v.ID,
+			Kind:    store.BlockRuleKind(v.Kind),
+			Value:   v.Value,
+			GuildID: discord.GuildID(v.GuildID),
+		}
+	}
+	return rules, nil
+}
+
+func (s *accountStore) AddBlockRule(ctx context.Context, rule store.BlockRule) (int64, error) {
+	id, err := s.q.AddBlockRule(ctx, queries.AddBlockRuleParams{
+		UserNumber: s.account.UserNumber,
+		Kind:       string(rule.Kind),
+		Value:      rule.Value,
+		GuildID:    int64(rule.GuildID),
+	})
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	return id, nil
+}
+
+func (s *accountStore) RemoveBlockRule(ctx context.Context, id int64) error {
+	n, err := s.q.RemoveBlockRule(ctx, queries.RemoveBlockRuleParams{
+		UserNumber: s.account.UserNumber,
+		ID:         id,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) SMSBudget(ctx context.Context) (store.SMSBudget, error) {
+	v, err := s.q.SmsBudget(ctx, s.account.UserNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.SMSBudget{OverBudget: store.OverBudgetHold}, nil
+		}
+		return store.SMSBudget{}, sqliteErr(err)
+	}
+	return store.SMSBudget{
+		DailySegments:   int(v.DailySegments),
+		MonthlySegments: int(v.MonthlySegments),
+		HourlyMessages:  int(v.HourlyMessages),
+		OverBudget:      store.OverBudgetAction(v.OverBudget),
+	}, nil
+}
+
+func (s *accountStore) SetSMSBudget(ctx context.Context, budget store.SMSBudget) error {
+	err := s.q.SetSmsBudget(ctx, queries.SetSmsBudgetParams{
+		UserNumber:      s.account.UserNumber,
+		DailySegments:   int64(budget.DailySegments),
+		MonthlySegments: int64(budget.MonthlySegments),
+		HourlyMessages:  int64(budget.HourlyMessages),
+		OverBudget:      string(budget.OverBudget),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) SMSUsage(ctx context.Context, since time.Time) (int, error) {
+	rows, err := s.q.SmsUsage(ctx, queries.SmsUsageParams{
+		UserNumber: s.account.UserNumber,
+		Day:        usageDay(since),
+	})
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	var segments int
+	for _, v := range rows {
+		segments += int(v)
+	}
+	return segments, nil
+}
+
+func (s *accountStore) AddSMSUsage(ctx context.Context, t time.Time, segments int) error {
+	err := s.q.AddSmsUsage(ctx, queries.AddSmsUsageParams{
+		UserNumber: s.account.UserNumber,
+		Day:        usageDay(t),
+		Segments:   int64(segments),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) PruneSMSUsage(ctx context.Context, before time.Time) error {
+	err := s.q.PruneSmsUsage(ctx, queries.PruneSmsUsageParams{
+		UserNumber: s.account.UserNumber,
+		Day:        usageDay(before),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) DeliverySettings(ctx context.Context) (store.DeliverySettings, error) {
+	v, err := s.q.DeliverySettings(ctx, s.account.UserNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.DeliverySettings{Mode: store.DeliveryRealtime}, nil
+		}
+		return store.DeliverySettings{}, sqliteErr(err)
+	}
+
+	var times []string
+	if v.DigestTimes != "" {
+		times = strings.Split(v.DigestTimes, ",")
+	}
+
+	return store.DeliverySettings{
+		Mode:           store.DeliveryMode(v.Mode),
+		DigestInterval: time.Duration(v.DigestInterval) * time.Second,
+		DigestTimes:    times,
+		NotifyDeletes:  v.NotifyDeletes != 0,
+	}, nil
+}
+
+func (s *accountStore) SetDeliverySettings(ctx context.Context, settings store.DeliverySettings) error {
+	err := s.q.SetDeliverySettings(ctx, queries.SetDeliverySettingsParams{
+		UserNumber:     s.account.UserNumber,
+		Mode:           string(settings.Mode),
+		DigestInterval: int64(settings.DigestInterval / time.Second),
+		DigestTimes:    strings.Join(settings.DigestTimes, ","),
+		NotifyDeletes:  boolToInt(settings.NotifyDeletes),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) Timezone(ctx context.Context) (string, error) {
+	name, err := s.q.Timezone(ctx, s.account.UserNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", sqliteErr(err)
+	}
+	return name, nil
+}
+
+func (s *accountStore) SetTimezone(ctx context.Context, name string) error {
+	err := s.q.SetTimezone(ctx, queries.SetTimezoneParams{
+		UserNumber: s.account.UserNumber,
+		Name:       name,
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) Focus(ctx context.Context) (store.Focus, error) {
+	v, err := s.q.Focus(ctx, s.account.UserNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.Focus{}, nil
+		}
+		return store.Focus{}, sqliteErr(err)
+	}
+	return store.Focus{
+		ChannelID: discord.ChannelID(v.ChannelID),
+		Until:     time.Unix(v.Until, 0),
+	}, nil
+}
+
+func (s *accountStore) SetFocus(ctx context.Context, focus store.Focus) error {
+	err := s.q.SetFocus(ctx, queries.SetFocusParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(focus.ChannelID),
+		Until:      focus.Until.Unix(),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) ClearFocus(ctx context.Context) error {
+	return sqliteErr(s.q.ClearFocus(ctx, s.account.UserNumber))
+}
+
+func (s *accountStore) PendingMessages(ctx context.Context) ([]store.PendingMessage, error) {
+	rows, err := s.q.PendingMessages(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	pending := make([]store.PendingMessage, len(rows))
+	for i, v := range rows {
+		pending[i] = store.PendingMessage{
+			ChannelID: discord.ChannelID(v.ChannelID),
+			MessageID: discord.MessageID(v.MessageID),
+		}
+	}
+	return pending, nil
+}
+
+func (s *accountStore) AddPendingMessage(ctx context.Context, msg store.PendingMessage) error {
+	err := s.q.AddPendingMessage(ctx, queries.AddPendingMessageParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(msg.ChannelID),
+		MessageID:  int64(msg.MessageID),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) RemovePendingMessage(ctx context.Context, msgID discord.MessageID) error {
+	err := s.q.RemovePendingMessage(ctx, queries.RemovePendingMessageParams{
+		UserNumber: s.account.UserNumber,
+		MessageID:  int64(msgID),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) DigestEntries(ctx context.Context) ([]store.DigestEntry, error) {
+	rows, err := s.q.DigestEntries(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	entries := make([]store.DigestEntry, len(rows))
+	for i, v := range rows {
+		entries[i] = store.DigestEntry{
+			ChannelID: discord.ChannelID(v.ChannelID),
+			MessageID: discord.MessageID(v.MessageID),
+			Author:    v.Author,
+			Content:   v.Content,
+		}
+	}
+	return entries, nil
+}
+
+func (s *accountStore) AddDigestEntry(ctx context.Context, entry store.DigestEntry) error {
+	err := s.q.AddDigestEntry(ctx, queries.AddDigestEntryParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(entry.ChannelID),
+		MessageID:  int64(entry.MessageID),
+		Author:     entry.Author,
+		Content:    entry.Content,
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) RemoveDigestEntry(ctx context.Context, msgID discord.MessageID) error {
+	err := s.q.RemoveDigestEntry(ctx, queries.RemoveDigestEntryParams{
+		UserNumber: s.account.UserNumber,
+		MessageID:  int64(msgID),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) ClearDigestEntries(ctx context.Context) error {
+	err := s.q.ClearDigestEntries(ctx, s.account.UserNumber)
+	return sqliteErr(err)
+}
+
+func (s *accountStore) ForwardedMessage(ctx context.Context, msgID discord.MessageID) (store.ForwardedMessage, error) {
+	v, err := s.q.ForwardedMessage(ctx, queries.ForwardedMessageParams{
+		UserNumber: s.account.UserNumber,
+		MessageID:  int64(msgID),
+	})
+	if err != nil {
+		return store.ForwardedMessage{}, sqliteErr(err)
+	}
+	return store.ForwardedMessage{
+		ChannelID:   discord.ChannelID(v.ChannelID),
+		MessageID:   discord.MessageID(v.MessageID),
+		ForwardedAt: time.Unix(v.ForwardedAt, 0),
+		ContentHash: v.ContentHash,
+		Author:      v.Author,
+	}, nil
+}
+
+func (s *accountStore) RecentForwardedMessage(ctx context.Context, n int) (store.ForwardedMessage, error) {
+	v, err := s.q.RecentForwardedMessage(ctx, queries.RecentForwardedMessageParams{
+		UserNumber: s.account.UserNumber,
+		Offset:     int64(n - 1),
+	})
+	if err != nil {
+		return store.ForwardedMessage{}, sqliteErr(err)
+	}
+	return store.ForwardedMessage{
+		ChannelID:   discord.ChannelID(v.ChannelID),
+		MessageID:   discord.MessageID(v.MessageID),
+		ForwardedAt: time.Unix(v.ForwardedAt, 0),
+		ContentHash: v.ContentHash,
+		Author:      v.Author,
+	}, nil
+}
+
+func (s *accountStore) SetMessageForwarded(ctx context.Context, msg store.ForwardedMessage) error {
+	err := s.q.SetMessageForwarded(ctx, queries.SetMessageForwardedParams{
+		UserNumber:  s.account.UserNumber,
+		ChannelID:   int64(msg.ChannelID),
+		MessageID:   int64(msg.MessageID),
+		ForwardedAt: msg.ForwardedAt.Unix(),
+		ContentHash: msg.ContentHash,
+		Author:      msg.Author,
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) PruneForwardedMessages(ctx context.Context, before time.Time) error {
+	err := s.q.PruneForwardedMessages(ctx, queries.PruneForwardedMessagesParams{
+		UserNumber:  s.account.UserNumber,
+		ForwardedAt: before.Unix(),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) HeldSMS(ctx context.Context) ([]store.HeldSMS, error) {
+	rows, err := s.q.HeldSms(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+
+	msgRows, err := s.q.HeldSmsMessages(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	forwarded := make(map[int64][]store.ForwardedMessage)
+	for _, v := range msgRows {
+		forwarded[v.HeldID] = append(forwarded[v.HeldID], store.ForwardedMessage{
+			ChannelID:   discord.ChannelID(v.ChannelID),
+			MessageID:   discord.MessageID(v.MessageID),
+			ContentHash: v.ContentHash,
+			Author:      v.Author,
+		})
+	}
+
+	held := make([]store.HeldSMS, len(rows))
+	for i, v := range rows {
+		held[i] = store.HeldSMS{
+			ID:        v.ID,
+			Text:      v.Text,
+			Forwarded: forwarded[v.ID],
+		}
+	}
+	return held, nil
+}
+
+func (s *accountStore) AddHeldSMS(ctx context.Context, held store.HeldSMS) (int64, error) {
+	var id int64
+	err := s.inTx(ctx, func(q *queries.Queries) error {
+		var err error
+		id, err = q.AddHeldSms(ctx, queries.AddHeldSmsParams{
+			UserNumber: s.account.UserNumber,
+			Text:       held.Text,
+		})
+		if err != nil {
+			return sqliteErr(err)
+		}
+
+		for _, msg := range held.Forwarded {
+			if err := q.AddHeldSmsMessage(ctx, queries.AddHeldSmsMessageParams{
+				HeldID:      id,
+				UserNumber:  s.account.UserNumber,
+				ChannelID:   int64(msg.ChannelID),
+				MessageID:   int64(msg.MessageID),
+				ContentHash: msg.ContentHash,
+				Author:      msg.Author,
+			}); err != nil {
+				return sqliteErr(err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *accountStore) RemoveHeldSMS(ctx context.Context, id int64) error {
+	if err := s.q.RemoveHeldSmsMessages(ctx, queries.RemoveHeldSmsMessagesParams{
+		UserNumber: s.account.UserNumber,
+		HeldID:     id,
+	}); err != nil {
+		return sqliteErr(err)
+	}
+
+	n, err := s.q.RemoveHeldSms(ctx, queries.RemoveHeldSmsParams{
+		UserNumber: s.account.UserNumber,
+		ID:         id,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) ChannelCursor(ctx context.Context, chID discord.ChannelID) (discord.MessageID, error) {
+	msgID, err := s.q.ChannelCursor(ctx, queries.ChannelCursorParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(chID),
+	})
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	return discord.MessageID(msgID), nil
+}
+
+func (s *accountStore) LatestChannelCursor(ctx context.Context) (discord.MessageID, error) {
+	msgID, err := s.q.LatestChannelCursor(ctx, s.account.UserNumber)
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	return discord.MessageID(msgID), nil
+}
+
+func (s *accountStore) SetChannelCursor(ctx context.Context, chID discord.ChannelID, msgID discord.MessageID) error {
+	err := s.q.SetChannelCursor(ctx, queries.SetChannelCursorParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(chID),
+		MessageID:  int64(msgID),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) RecentSentMessage(ctx context.Context, n int) (store.SentMessage, error) {
+	v, err := s.q.RecentSentMessage(ctx, queries.RecentSentMessageParams{
+		UserNumber: s.account.UserNumber,
+		Offset:     int64(n - 1),
+	})
+	if err != nil {
+		return store.SentMessage{}, sqliteErr(err)
+	}
+	return store.SentMessage{
+		ChannelID: discord.ChannelID(v.ChannelID),
+		MessageID: discord.MessageID(v.MessageID),
+		SentAt:    time.Unix(v.SentAt, 0),
+	}, nil
+}
+
+func (s *accountStore) AddSentMessage(ctx context.Context, msg store.SentMessage) error {
+	err := s.q.AddSentMessage(ctx, queries.AddSentMessageParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(msg.ChannelID),
+		MessageID:  int64(msg.MessageID),
+		SentAt:     msg.SentAt.Unix(),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) RemoveSentMessage(ctx context.Context, msgID discord.MessageID) error {
+	err := s.q.RemoveSentMessage(ctx, queries.RemoveSentMessageParams{
+		UserNumber: s.account.UserNumber,
+		MessageID:  int64(msgID),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) PruneSentMessages(ctx context.Context, before time.Time) error {
+	err := s.q.PruneSentMessages(ctx, queries.PruneSentMessagesParams{
+		UserNumber: s.account.UserNumber,
+		SentAt:     before.Unix(),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) ChannelUsage(ctx context.Context) (map[discord.ChannelID]store.ChannelUsage, error) {
+	rows, err := s.q.ChannelUsage(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	usage := make(map[discord.ChannelID]store.ChannelUsage, len(rows))
+	for _, v := range rows {
+		usage[discord.ChannelID(v.ChannelID)] = store.ChannelUsage{
+			SendCount:  int(v.SendCount),
+			LastSentAt: time.Unix(v.LastSentAt, 0),
+		}
+	}
+	return usage, nil
+}
+
+func (s *accountStore) AddChannelSend(ctx context.Context, chID discord.ChannelID, at time.Time) error {
+	err := s.q.AddChannelSend(ctx, queries.AddChannelSendParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(chID),
+		LastSentAt: at.Unix(),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) PruneChannelUsage(ctx context.Context, before time.Time) error {
+	err := s.q.PruneChannelUsage(ctx, queries.PruneChannelUsageParams{
+		UserNumber: s.account.UserNumber,
+		LastSentAt: before.Unix(),
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) ScheduledMessages(ctx context.Context) ([]store.ScheduledMessage, error) {
+	rows, err := s.q.ScheduledMessages(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	msgs := make([]store.ScheduledMessage, len(rows))
+	for i, v := range rows {
+		msgs[i] = store.ScheduledMessage{
+			ID:        v.ID,
+			ChannelID: discord.ChannelID(v.ChannelID),
+			UserID:    discord.UserID(v.UserID),
+			Content:   v.Content,
+			SendAt:    time.Unix(v.SendAt, 0),
+			Attempts:  int(v.Attempts),
+		}
+	}
+	return msgs, nil
+}
+
+func (s *accountStore) AddScheduledMessage(ctx context.Context, msg store.ScheduledMessage) (int64, error) {
+	id, err := s.q.AddScheduledMessage(ctx, queries.AddScheduledMessageParams{
+		UserNumber: s.account.UserNumber,
+		ChannelID:  int64(msg.ChannelID),
+		UserID:     int64(msg.UserID),
+		Content:    msg.Content,
+		SendAt:     msg.SendAt.Unix(),
+	})
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	return id, nil
+}
+
+func (s *accountStore) RetryScheduledMessage(ctx context.Context, id int64, sendAt time.Time) error {
+	n, err := s.q.RetryScheduledMessage(ctx, queries.RetryScheduledMessageParams{
+		SendAt:     sendAt.Unix(),
+		UserNumber: s.account.UserNumber,
+		ID:         id,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) RemoveScheduledMessage(ctx context.Context, id int64) error {
+	n, err := s.q.RemoveScheduledMessage(ctx, queries.RemoveScheduledMessageParams{
+		UserNumber: s.account.UserNumber,
+		ID:         id,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) Reminders(ctx context.Context) ([]store.Reminder, error) {
+	rows, err := s.q.Reminders(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	reminders := make([]store.Reminder, len(rows))
+	for i, v := range rows {
+		reminders[i] = store.Reminder{
+			ID:       v.ID,
+			RemindAt: time.Unix(v.RemindAt, 0),
+			Note:     v.Note,
+			Quote:    v.Quote,
+			Link:     v.Link,
+		}
+	}
+	return reminders, nil
+}
+
+func (s *accountStore) AddReminder(ctx context.Context, reminder store.Reminder) (int64, error) {
+	id, err := s.q.AddReminder(ctx, queries.AddReminderParams{
+		UserNumber: s.account.UserNumber,
+		RemindAt:   reminder.RemindAt.Unix(),
+		Note:       reminder.Note,
+		Quote:      reminder.Quote,
+		Link:       reminder.Link,
+	})
+	if err != nil {
+		return 0, sqliteErr(err)
+	}
+	return id, nil
+}
+
+func (s *accountStore) RemoveReminder(ctx context.Context, id int64) error {
+	n, err := s.q.RemoveReminder(ctx, queries.RemoveReminderParams{
+		UserNumber: s.account.UserNumber,
+		ID:         id,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+func (s *accountStore) Template(ctx context.Context, name string) (string, error) {
+	text, err := s.q.Template(ctx, queries.TemplateParams{
+		UserNumber: s.account.UserNumber,
+		Name:       name,
+	})
+	if err != nil {
+		return "", sqliteErr(err)
+	}
+	return text, nil
+}
+
+func (s *accountStore) Templates(ctx context.Context) (map[string]string, error) {
+	rows, err := s.q.Templates(ctx, s.account.UserNumber)
+	if err != nil {
+		return nil, sqliteErr(err)
+	}
+	templates := make(map[string]string, len(rows))
+	for _, v := range rows {
+		templates[v.Name] = v.Text
+	}
+	return templates, nil
+}
+
+func (s *accountStore) SetTemplate(ctx context.Context, name, text string) error {
+	err := s.q.SetTemplate(ctx, queries.SetTemplateParams{
+		UserNumber: s.account.UserNumber,
+		Name:       name,
+		Text:       text,
+	})
+	return sqliteErr(err)
+}
+
+func (s *accountStore) RemoveTemplate(ctx context.Context, name string) error {
+	n, err := s.q.RemoveTemplate(ctx, queries.RemoveTemplateParams{
+		UserNumber: s.account.UserNumber,
+		Name:       name,
+	})
+	if err != nil {
+		return sqliteErr(err)
+	}
+	if n == 0 {
+		return store.ErrNotFound
+	}
+	return nil
+}
+
+// usageDay formats the day of the given time for the sms_usage table.
+func usageDay(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
+
+func boolToInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func sqliteErr(err error) error {
 	if errors.Is(err, sql.ErrNoRows) {
 		return store.ErrNotFound
@@ -41,6 +41,189 @@ type AccountStore interface {
 	ChannelFromNickname(context.Context, string) (discord.ChannelID, error)
 	// SetChannelNickname sets the nickname of a channel.
 	SetChannelNickname(context.Context, discord.ChannelID, string) error
+	// RemoveChannelNickname removes a channel nickname. It returns ErrNotFound
+	// if there is no such nickname.
+	RemoveChannelNickname(context.Context, string) error
+	// RenameChannelNickname renames a channel nickname. It returns ErrNotFound
+	// if there is no such nickname.
+	RenameChannelNickname(ctx context.Context, old, new string) error
+
+	// GuildAlias returns the alias of a guild.
+	GuildAlias(context.Context, discord.GuildID) (string, error)
+	// GuildAliases returns all guild aliases.
+	GuildAliases(context.Context) (map[discord.GuildID]string, error)
+	// GuildFromAlias returns the guild ID from an alias.
+	GuildFromAlias(context.Context, string) (discord.GuildID, error)
+	// SetGuildAlias sets the alias of a guild.
+	SetGuildAlias(context.Context, discord.GuildID, string) error
+	// RemoveGuildAlias removes a guild alias. It returns ErrNotFound if there
+	// is no such alias.
+	RemoveGuildAlias(context.Context, string) error
+	// RenameGuildAlias renames a guild alias. It returns ErrNotFound if there
+	// is no such alias.
+	RenameGuildAlias(ctx context.Context, old, new string) error
+
+	// WatchRules returns all keyword watch rules.
+	WatchRules(context.Context) ([]WatchRule, error)
+	// AddWatchRule adds a keyword watch rule and returns its ID.
+	AddWatchRule(context.Context, WatchRule) (int64, error)
+	// RemoveWatchRule removes the keyword watch rule with the given ID.
+	RemoveWatchRule(context.Context, int64) error
+
+	// VIPUsers returns the Discord users whose messages bypass mutes and
+	// delays.
+	VIPUsers(context.Context) ([]discord.UserID, error)
+	// AddVIPUser adds a Discord user to the VIP list.
+	AddVIPUser(context.Context, discord.UserID) error
+	// RemoveVIPUser removes a Discord user from the VIP list.
+	RemoveVIPUser(context.Context, discord.UserID) error
+
+	// BlockRules returns all block rules.
+	BlockRules(context.Context) ([]BlockRule, error)
+	// AddBlockRule adds a block rule and returns its ID.
+	AddBlockRule(context.Context, BlockRule) (int64, error)
+	// RemoveBlockRule removes the block rule with the given ID.
+	RemoveBlockRule(context.Context, int64) error
+
+	// SMSBudget returns the SMS budget. The zero value is returned if no
+	// budget was ever set.
+	SMSBudget(context.Context) (SMSBudget, error)
+	// SetSMSBudget sets the SMS budget.
+	SetSMSBudget(context.Context, SMSBudget) error
+	// SMSUsage returns the number of SMS segments sent since the day of the
+	// given time.
+	SMSUsage(context.Context, time.Time) (int, error)
+	// AddSMSUsage adds the number of SMS segments sent on the day of the given
+	// time.
+	AddSMSUsage(context.Context, time.Time, int) error
+	// PruneSMSUsage forgets about SMS segments sent before the day of the
+	// given time.
+	PruneSMSUsage(context.Context, time.Time) error
+	// HeldSMS returns all texts held back because of the SMS budget, ordered
+	// from earliest.
+	HeldSMS(context.Context) ([]HeldSMS, error)
+	// AddHeldSMS holds back a text because of the SMS budget and returns its
+	// ID.
+	AddHeldSMS(context.Context, HeldSMS) (int64, error)
+	// RemoveHeldSMS removes the held text with the given ID. It returns
+	// ErrNotFound if there is no such text.
+	RemoveHeldSMS(context.Context, int64) error
+
+	// DeliverySettings returns how notifications are delivered. Realtime
+	// delivery is returned if nothing was ever set.
+	DeliverySettings(context.Context) (DeliverySettings, error)
+	// SetDeliverySettings sets how notifications are delivered.
+	SetDeliverySettings(context.Context, DeliverySettings) error
+
+	// Timezone returns the name of the IANA time zone that times of day are
+	// given in. An empty string is returned if none was ever set.
+	Timezone(context.Context) (string, error)
+	// SetTimezone sets the name of the IANA time zone that times of day are
+	// given in.
+	SetTimezone(context.Context, string) error
+
+	// Focus returns the channel that is focused on. The zero value is
+	// returned if focus mode is off.
+	Focus(context.Context) (Focus, error)
+	// SetFocus focuses on a channel, replacing any previous focus.
+	SetFocus(context.Context, Focus) error
+	// ClearFocus turns focus mode off.
+	ClearFocus(context.Context) error
+
+	// PendingMessages returns all messages that are queued for sending,
+	// ordered from earliest.
+	PendingMessages(context.Context) ([]PendingMessage, error)
+	// AddPendingMessage adds a message that is queued for sending.
+	AddPendingMessage(context.Context, PendingMessage) error
+	// RemovePendingMessage removes a message that is no longer queued for
+	// sending.
+	RemovePendingMessage(context.Context, discord.MessageID) error
+
+	// DigestEntries returns all messages waiting for the next digest, ordered
+	// from earliest.
+	DigestEntries(context.Context) ([]DigestEntry, error)
+	// AddDigestEntry adds a message to wait for the next digest.
+	AddDigestEntry(context.Context, DigestEntry) error
+	// RemoveDigestEntry removes a message from the next digest.
+	RemoveDigestEntry(context.Context, discord.MessageID) error
+	// ClearDigestEntries removes all messages waiting for the next digest.
+	ClearDigestEntries(context.Context) error
+
+	// ForwardedMessage returns the record of when a message was last forwarded.
+	// It returns ErrNotFound if the message was never forwarded.
+	ForwardedMessage(context.Context, discord.MessageID) (ForwardedMessage, error)
+	// RecentForwardedMessage returns the nth most recently forwarded message,
+	// starting from 1. It returns ErrNotFound if there is no such message.
+	RecentForwardedMessage(ctx context.Context, n int) (ForwardedMessage, error)
+	// SetMessageForwarded records that a message was forwarded.
+	SetMessageForwarded(context.Context, ForwardedMessage) error
+	// PruneForwardedMessages forgets about messages forwarded before the given
+	// time.
+	PruneForwardedMessages(context.Context, time.Time) error
+
+	// ChannelCursor returns the ID of the last message processed in the
+	// channel. It returns ErrNotFound if no message was processed yet.
+	ChannelCursor(context.Context, discord.ChannelID) (discord.MessageID, error)
+	// LatestChannelCursor returns the ID of the last message processed in any
+	// channel. It returns ErrNotFound if no message was processed yet.
+	LatestChannelCursor(context.Context) (discord.MessageID, error)
+	// SetChannelCursor sets the ID of the last message processed in the
+	// channel.
+	SetChannelCursor(context.Context, discord.ChannelID, discord.MessageID) error
+
+	// RecentSentMessage returns the nth most recent message sent on behalf of
+	// the user, starting from 1. It returns ErrNotFound if there is no such
+	// message.
+	RecentSentMessage(ctx context.Context, n int) (SentMessage, error)
+	// AddSentMessage records a message sent on behalf of the user.
+	AddSentMessage(context.Context, SentMessage) error
+	// RemoveSentMessage forgets about a message sent on behalf of the user.
+	RemoveSentMessage(context.Context, discord.MessageID) error
+	// PruneSentMessages forgets about messages sent before the given time.
+	PruneSentMessages(context.Context, time.Time) error
+
+	// ChannelUsage returns how often and how recently the user sent messages
+	// to each channel.
+	ChannelUsage(context.Context) (map[discord.ChannelID]ChannelUsage, error)
+	// AddChannelSend records that the user sent a message to the channel at
+	// the given time.
+	AddChannelSend(context.Context, discord.ChannelID, time.Time) error
+	// PruneChannelUsage forgets about channels that the user last sent a
+	// message to before the given time.
+	PruneChannelUsage(context.Context, time.Time) error
+
+	// ScheduledMessages returns all messages scheduled to be sent later,
+	// ordered from earliest.
+	ScheduledMessages(context.Context) ([]ScheduledMessage, error)
+	// AddScheduledMessage schedules a message to be sent later and returns its
+	// ID.
+	AddScheduledMessage(context.Context, ScheduledMessage) (int64, error)
+	// RetryScheduledMessage reschedules the message with the given ID to the
+	// given time after it failed to send, counting the attempt. It returns
+	// ErrNotFound if there is no such message.
+	RetryScheduledMessage(context.Context, int64, time.Time) error
+	// RemoveScheduledMessage removes the scheduled message with the given ID.
+	// It returns ErrNotFound if there is no such message.
+	RemoveScheduledMessage(context.Context, int64) error
+
+	// Reminders returns all reminders, ordered from earliest.
+	Reminders(context.Context) ([]Reminder, error)
+	// AddReminder adds a reminder and returns its ID.
+	AddReminder(context.Context, Reminder) (int64, error)
+	// RemoveReminder removes the reminder with the given ID. It returns
+	// ErrNotFound if there is no such reminder.
+	RemoveReminder(context.Context, int64) error
+
+	// Template returns the text of a quick-reply template. It returns
+	// ErrNotFound if there is no such template.
+	Template(context.Context, string) (string, error)
+	// Templates returns all quick-reply templates by name.
+	Templates(context.Context) (map[string]string, error)
+	// SetTemplate sets the text of a quick-reply template.
+	SetTemplate(ctx context.Context, name, text string) error
+	// RemoveTemplate removes a quick-reply template. It returns ErrNotFound if
+	// there is no such template.
+	RemoveTemplate(context.Context, string) error
 }
 
 type Account struct {
@@ -49,6 +232,171 @@ type Account struct {
 	DiscordToken string
 }
 
+// WatchRule is a rule that forwards guild messages matching a keyword or a
+// regular expression, even if they don't mention the user.
+type WatchRule struct {
+	ID      int64 // key
+	Pattern string
+	IsRegex bool
+	// GuildID, if valid, limits the rule to messages in this guild.
+	GuildID discord.GuildID
+	// ChannelID, if valid, limits the rule to messages in this channel.
+	ChannelID discord.ChannelID
+}
+
+// BlockRuleKind is the kind of a block rule.
+type BlockRuleKind string
+
+const (
+	// BlockUser blocks messages from the user whose ID is the rule value.
+	BlockUser BlockRuleKind = "user"
+	// BlockRole blocks messages from members that have the role whose ID is
+	// the rule value.
+	BlockRole BlockRuleKind = "role"
+	// BlockContent blocks messages whose content matches the regular
+	// expression in the rule value.
+	BlockContent BlockRuleKind = "content"
+)
+
+// BlockRule is a rule that prevents messages from being forwarded.
+type BlockRule struct {
+	ID    int64 // key
+	Kind  BlockRuleKind
+	Value string
+	// GuildID is the guild that the role belongs to for BlockRole rules.
+	GuildID discord.GuildID
+}
+
+// OverBudgetAction is what happens to messages once the SMS budget is
+// exceeded.
+type OverBudgetAction string
+
+const (
+	// OverBudgetHold holds messages and sends them as a digest once the budget
+	// allows it again.
+	OverBudgetHold OverBudgetAction = "hold"
+	// OverBudgetDrop drops messages and sends a single notice that the budget
+	// was reached.
+	OverBudgetDrop OverBudgetAction = "drop"
+)
+
+// SMSBudget limits how many SMS are sent for an account. Zero limits mean
+// unlimited.
+type SMSBudget struct {
+	DailySegments   int
+	MonthlySegments int
+	HourlyMessages  int
+	OverBudget      OverBudgetAction
+}
+
+// HeldSMS is a text that was held back because of the SMS budget, to be sent
+// once the budget allows it again.
+type HeldSMS struct {
+	ID   int64 // key
+	Text string
+	// Forwarded are the messages that the text forwards. Their ForwardedAt is
+	// unset until the text is sent.
+	Forwarded []ForwardedMessage
+}
+
+// DeliveryMode is how notifications are delivered.
+type DeliveryMode string
+
+const (
+	// DeliveryRealtime sends notifications as they come in.
+	DeliveryRealtime DeliveryMode = "realtime"
+	// DeliveryDigest collects notifications and sends them periodically as a
+	// digest.
+	DeliveryDigest DeliveryMode = "digest"
+)
+
+// DeliverySettings describes how notifications are delivered.
+type DeliverySettings struct {
+	Mode DeliveryMode
+	// DigestInterval is the interval between digests.
+	DigestInterval time.Duration
+	// DigestTimes are the times of day in the 15:04 format at which digests
+	// are sent. It is used over DigestInterval if not empty.
+	DigestTimes []string
+	// NotifyDeletes is true if a notice should be sent when a forwarded
+	// message is deleted.
+	NotifyDeletes bool
+}
+
+// PendingMessage is a message that is queued for sending.
+type PendingMessage struct {
+	ChannelID discord.ChannelID
+	MessageID discord.MessageID // key
+}
+
+// ForwardedMessage is a record of a message that was forwarded over SMS.
+type ForwardedMessage struct {
+	ChannelID   discord.ChannelID
+	MessageID   discord.MessageID // key
+	ForwardedAt time.Time
+	// ContentHash is the hash of the message content as it was forwarded. It
+	// is empty for messages forwarded before content hashes were recorded.
+	ContentHash string
+	// Author is the display name of the message author.
+	Author string
+}
+
+// SentMessage is a message that was sent to Discord on behalf of the user.
+type SentMessage struct {
+	ChannelID discord.ChannelID
+	MessageID discord.MessageID // key
+	SentAt    time.Time
+}
+
+// ChannelUsage describes how the user sends messages to a channel.
+type ChannelUsage struct {
+	SendCount  int
+	LastSentAt time.Time
+}
+
+// Focus is a channel that the user focuses on for a while, holding back
+// messages from all other channels.
+type Focus struct {
+	ChannelID discord.ChannelID
+	Until     time.Time
+}
+
+// ScheduledMessage is a Discord message that is sent at a later time.
+type ScheduledMessage struct {
+	ID        int64 // key
+	ChannelID discord.ChannelID
+	// UserID is the user that the message is sent to if there was no DM with
+	// them yet when it was scheduled, in which case ChannelID is zero. The DM
+	// is created when the message is sent.
+	UserID  discord.UserID
+	Content string
+	SendAt  time.Time
+	// Attempts is the number of times that sending the message failed.
+	Attempts int
+}
+
+// Reminder is a reminder that is sent to the user over SMS.
+type Reminder struct {
+	ID       int64 // key
+	RemindAt time.Time
+	// Note is the text that the user wanted to be reminded of.
+	Note string
+	// Quote is the content of the Discord message that the reminder points
+	// to, if any, including its author.
+	Quote string
+	// Link is the jump link to the Discord message that the reminder points
+	// to, if any.
+	Link string
+}
+
+// DigestEntry is a rendered message that is waiting for the next digest.
+type DigestEntry struct {
+	ChannelID discord.ChannelID
+	MessageID discord.MessageID // key
+	Author    string
+	Content   string
+}
+
 // InternalError is returned by stores in case of an internal error.
 type InternalError struct {
 	Err error